@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/repository"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+// Issues the first key of a tenant, after which keys can be managed through /api/admin/api-keys.
+// Usage: go run ./cmd/apikey -tenant default -name bootstrap -owner ops -scopes admin -expires 720h
+func main() {
+	tenant := flag.String("tenant", config.DefaultTenant, "tenant the key authenticates")
+	name := flag.String("name", "", "name of the key")
+	owner := flag.String("owner", "", "owner of the key")
+	scopes := flag.String("scopes", "admin", "space separated scopes granted to the key")
+	expires := flag.Duration("expires", 0, "lifetime of the key, 0 for no expiry")
+	flag.Parse()
+
+	request := web.ApiKeyCreateRequest{Name: *name, Owner: *owner, Scopes: strings.Fields(*scopes)}
+	if *expires > 0 {
+		expiresAt := time.Now().UTC().Add(*expires)
+		request.ExpiresAt = &expiresAt
+	}
+
+	appConfig, err := config.LoadAppConfig(nil)
+	helper.PanicIfError(err)
+	db := config.NewDB(appConfig.Database)
+	defer db.Close()
+
+	apiKeyService := service.NewApiKeyService(repository.NewApiKeyRepository(), db, helper.Validator(), nil)
+	response := apiKeyService.Issue(helper.WithTenant(context.Background(), *tenant), request)
+
+	fmt.Fprintf(os.Stderr, "issued api key %d (%s) for tenant %s, it is shown only once:\n", response.Id, response.Prefix, *tenant)
+	fmt.Println(response.Key)
+}
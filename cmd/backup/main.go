@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/repository"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+// Usage: go run ./cmd/backup -output categories.json.gz -gzip
+func main() {
+	output := flag.String("output", "-", "archive file to write, - for stdout")
+	compress := flag.Bool("gzip", false, "gzip the archive")
+	tenant := flag.String("tenant", config.DefaultTenant, "tenant whose categories are processed")
+	flag.Parse()
+
+	appConfig, err := config.LoadAppConfig(nil)
+	helper.PanicIfError(err)
+	db := config.NewDB(appConfig.Database)
+	defer db.Close()
+
+	backupService := service.NewBackupService(repository.NewCategoryRepository(), repository.NewProductRepository(), db, helper.Validator())
+	archive := backupService.Backup(helper.WithTenant(context.Background(), *tenant))
+
+	var writer io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		helper.PanicIfError(err)
+		defer file.Close()
+		writer = file
+	}
+
+	helper.WriteArchive(writer, archive, *compress)
+	fmt.Fprintf(os.Stderr, "backed up %d categories (schema version %d)\n", archive.CategoryCount, archive.SchemaVersion)
+}
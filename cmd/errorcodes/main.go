@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mrakhaf/golang-restful-api/exeption"
+)
+
+// Regenerates the error code table and the ErrorCode schema of apispec.json from exeption.ErrorCodes.
+// Usage: go generate ./exeption
+func main() {
+	specPath := flag.String("spec", "apispec.json", "apispec.json to update")
+	docsPath := flag.String("docs", "docs/error-codes.md", "markdown table to write")
+	flag.Parse()
+
+	spec, err := os.ReadFile(*specPath)
+	if err != nil {
+		fail(err)
+	}
+	spec, err = setSchema(spec, "ErrorCode", errorCodeSchema(exeption.ErrorCodes))
+	if err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile(*specPath, spec, 0644); err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile(*docsPath, []byte(errorCodeTable(exeption.ErrorCodes)), 0644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+func errorCodeTable(codes []exeption.ErrorCode) string {
+	var table strings.Builder
+	table.WriteString("# Error codes\n\n")
+	table.WriteString("<!-- Generated by cmd/errorcodes from exeption/error_code.go, do not edit. -->\n\n")
+	table.WriteString("Every error response carries one of these codes as `error_code`, next to its HTTP status.\n\n")
+	table.WriteString("| Code | Status | Message |\n")
+	table.WriteString("| --- | --- | --- |\n")
+	for _, code := range codes {
+		fmt.Fprintf(&table, "| `%s` | %d | %s |\n", code.Code, code.Status, code.Message)
+	}
+	return table.String()
+}
+
+func errorCodeSchema(codes []exeption.ErrorCode) map[string]interface{} {
+	names := make([]string, 0, len(codes))
+	lines := make([]string, 0, len(codes))
+	for _, code := range codes {
+		names = append(names, code.Code)
+		lines = append(lines, fmt.Sprintf("%s (%d): %s", code.Code, code.Status, code.Message))
+	}
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Sent as error_code in every error response. Generated from exeption/error_code.go.\n" + strings.Join(lines, "\n"),
+		"enum":        names,
+	}
+}
+
+// setSchema writes schema as components.schemas[name], replacing the previous one, and edits
+// apispec.json as text so the hand written formatting of the other entries is left alone.
+func setSchema(spec []byte, name string, schema map[string]interface{}) ([]byte, error) {
+	if !json.Valid(spec) {
+		return nil, fmt.Errorf("apispec.json is not valid JSON")
+	}
+	text := string(spec)
+	schemasIndex := strings.Index(text, "\n\t\t\"schemas\"")
+	if schemasIndex < 0 {
+		return nil, fmt.Errorf("apispec.json has no schemas object")
+	}
+	schemasOpen := strings.Index(text[schemasIndex:], "{") + schemasIndex
+	schemasClose := matchingBrace(text, schemasOpen)
+	if schemasClose < 0 {
+		return nil, fmt.Errorf("apispec.json schemas object is not closed")
+	}
+
+	encoded, err := json.MarshalIndent(schema, "\t\t\t", "\t")
+	if err != nil {
+		return nil, err
+	}
+	member := "\"" + name + "\": " + string(bytes.TrimSpace(encoded))
+
+	var result string
+	if keyIndex := strings.Index(text[schemasOpen:schemasClose], "\n\t\t\t\""+name+"\": {"); keyIndex >= 0 {
+		keyIndex += schemasOpen + 1
+		open := strings.Index(text[keyIndex:], "{") + keyIndex
+		result = text[:keyIndex] + "\t\t\t" + member + text[matchingBrace(text, open)+1:]
+	} else {
+		existing := strings.TrimRight(text[schemasOpen+1:schemasClose], " \t\n")
+		separator := ""
+		if strings.TrimSpace(existing) != "" {
+			separator = ","
+		}
+		result = text[:schemasOpen+1] + existing + separator + "\n\t\t\t" + member + "\n\t\t" + text[schemasClose:]
+	}
+	if !json.Valid([]byte(result)) {
+		return nil, fmt.Errorf("updating apispec.json produced invalid JSON")
+	}
+	return []byte(result), nil
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or -1.
+func matchingBrace(text string, open int) int {
+	level := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			level++
+		case '}':
+			level--
+			if level == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/repository"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+// Usage: go run ./cmd/restore -input categories.json.gz -preserve-ids -replace
+func main() {
+	input := flag.String("input", "-", "archive file to read, - for stdin; plain and gzip archives are both accepted")
+	preserveIds := flag.Bool("preserve-ids", false, "keep the category ids stored in the archive")
+	replace := flag.Bool("replace", false, "delete existing categories before restoring")
+	tenant := flag.String("tenant", config.DefaultTenant, "tenant whose categories are processed")
+	flag.Parse()
+
+	var reader io.Reader = os.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		helper.PanicIfError(err)
+		defer file.Close()
+		reader = file
+	}
+
+	archive := web.BackupArchive{}
+	err := helper.ReadArchive(reader, &archive)
+	helper.PanicIfError(err)
+
+	appConfig, err := config.LoadAppConfig(nil)
+	helper.PanicIfError(err)
+	db := config.NewDB(appConfig.Database)
+	defer db.Close()
+
+	backupService := service.NewBackupService(repository.NewCategoryRepository(), repository.NewProductRepository(), db, helper.Validator())
+	response := backupService.Restore(helper.WithTenant(context.Background(), *tenant), archive, web.RestoreRequest{
+		PreserveIds: *preserveIds,
+		Replace:     *replace,
+	})
+
+	fmt.Fprintf(os.Stderr, "restored %d categories (schema version %d)\n", response.Restored, response.SchemaVersion)
+}
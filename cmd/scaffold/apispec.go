@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// addToApiSpec inserts the paths and schemas of resource into apispec.json as text,
+// so the hand written formatting of the existing entries is left alone.
+func addToApiSpec(spec []byte, resource Resource) ([]byte, error) {
+	var parsed struct {
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(spec, &parsed); err != nil {
+		return nil, fmt.Errorf("apispec.json is not valid JSON: %w", err)
+	}
+	if _, ok := parsed.Paths[resource.Path]; ok {
+		return nil, fmt.Errorf("apispec.json already documents %s", resource.Path)
+	}
+
+	properties := map[string]interface{}{}
+	for _, field := range resource.Fields {
+		properties[field.Json] = map[string]string{"type": field.SpecType}
+	}
+	responseProperties := map[string]interface{}{"id": map[string]string{"type": "number"}}
+	for name, property := range properties {
+		responseProperties[name] = property
+	}
+
+	schemaRef := "#/components/schemas/" + resource.Name
+	requestRef := "#/components/schemas/CreateOrUpdate" + resource.Name
+	security := []map[string][]string{{"CategoryAuth": {}}, {"BearerAuth": {}}, {"OAuth2": {}}, {"HmacAuth": {}}}
+	tags := []string{resource.Name + " API"}
+	idParameter := []map[string]interface{}{{
+		"name":        resource.IdParam,
+		"in":          "path",
+		"description": resource.Name + " id",
+		"required":    true,
+		"schema":      map[string]string{"type": "string"},
+	}}
+	requestBody := map[string]interface{}{
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": map[string]string{"$ref": requestRef}},
+		},
+	}
+	envelope := func(description string, data interface{}) map[string]interface{} {
+		properties := map[string]interface{}{
+			"code":   map[string]string{"type": "number"},
+			"status": map[string]string{"type": "string"},
+		}
+		if data != nil {
+			properties["data"] = data
+		}
+		return map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": description,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{"type": "object", "properties": properties},
+					},
+				},
+			},
+		}
+	}
+	operation := func(summary string, parameters interface{}, body interface{}, responses interface{}) map[string]interface{} {
+		result := map[string]interface{}{
+			"security":    security,
+			"tags":        tags,
+			"summary":     summary,
+			"description": summary,
+			"responses":   responses,
+		}
+		if parameters != nil {
+			result["parameters"] = parameters
+		}
+		if body != nil {
+			result["requestBody"] = body
+		}
+		return result
+	}
+
+	single := map[string]string{"$ref": schemaRef}
+	list := map[string]interface{}{"type": "array", "items": single}
+	paths := map[string]interface{}{
+		resource.Path: map[string]interface{}{
+			"get":  operation("List all "+resource.Plural, nil, nil, envelope("Success get all "+resource.Plural, list)),
+			"post": operation("Create new "+resource.Title, nil, requestBody, envelope("Success create "+resource.Title, single)),
+		},
+		resource.Path + "/{" + resource.IdParam + "}": map[string]interface{}{
+			"get":    operation("Get "+resource.Title+" by id", idParameter, nil, envelope("Success get "+resource.Title+" by id", single)),
+			"put":    operation("Update "+resource.Title+" by id", idParameter, requestBody, envelope("Success update "+resource.Title+" by id", single)),
+			"delete": operation("Delete "+resource.Title+" by id", idParameter, nil, envelope("Success delete "+resource.Title+" by id", nil)),
+		},
+	}
+	schemas := map[string]interface{}{
+		"CreateOrUpdate" + resource.Name: map[string]interface{}{"type": "object", "properties": properties},
+		resource.Name:                    map[string]interface{}{"type": "object", "properties": responseProperties},
+	}
+
+	spec, err := appendMembers(spec, "\t\"paths\"", paths, 2)
+	if err != nil {
+		return nil, err
+	}
+	return appendMembers(spec, "\t\t\"schemas\"", schemas, 3)
+}
+
+// appendMembers adds members at the end of the object whose key line starts with marker,
+// indenting them depth tabs deep.
+func appendMembers(spec []byte, marker string, members map[string]interface{}, depth int) ([]byte, error) {
+	text := string(spec)
+	keyIndex := strings.Index(text, "\n"+marker)
+	if keyIndex < 0 {
+		return nil, fmt.Errorf("apispec.json has no %s object", strings.TrimSpace(marker))
+	}
+	open := strings.Index(text[keyIndex:], "{") + keyIndex
+
+	level, close := 0, -1
+	for i := open; i < len(text) && close < 0; i++ {
+		switch text[i] {
+		case '{':
+			level++
+		case '}':
+			level--
+			if level == 0 {
+				close = i
+			}
+		}
+	}
+	if close < 0 {
+		return nil, fmt.Errorf("apispec.json %s object is not closed", strings.TrimSpace(marker))
+	}
+
+	encoded, err := json.MarshalIndent(members, strings.Repeat("\t", depth-1), "\t")
+	if err != nil {
+		return nil, err
+	}
+	encoded = bytes.TrimSpace(encoded)
+	body := strings.TrimSpace(string(encoded[1 : len(encoded)-1]))
+
+	existing := strings.TrimRight(text[open+1:close], " \t\n")
+	separator := ""
+	if strings.TrimSpace(existing) != "" {
+		separator = ","
+	}
+
+	result := text[:open+1] + existing + separator + "\n" + strings.Repeat("\t", depth) + body + "\n" + strings.Repeat("\t", depth-1) + text[close:]
+	if !json.Valid([]byte(result)) {
+		return nil, fmt.Errorf("updating apispec.json produced invalid JSON")
+	}
+	return []byte(result), nil
+}
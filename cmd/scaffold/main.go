@@ -0,0 +1,185 @@
+// Command scaffold generates a tenant-scoped CRUD resource on top of the generic
+// repository, service and controller building blocks.
+//
+// Usage:
+//
+//	go run ./cmd/scaffold supplier name:string:required,max=200 phone:string:max=20 rating:int:min=0,max=5
+//
+// Every field is name:type[:validate rules], with type one of string, int, int64, float64 or bool.
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+var migrationNumber = regexp.MustCompile(`^(\d+)_`)
+
+func main() {
+	dir := flag.String("dir", ".", "root of the repository to generate into")
+	force := flag.Bool("force", false, "overwrite files that already exist")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: go run ./cmd/scaffold [-dir .] [-force] resource field:type[:rules]...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*dir, *force, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "scaffold:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, force bool, name string, fieldSpecs []string) error {
+	module, err := readModule(dir)
+	if err != nil {
+		return err
+	}
+
+	resource, err := NewResource(module, name, fieldSpecs)
+	if err != nil {
+		return err
+	}
+
+	resource.Migration, err = nextMigration(filepath.Join(dir, "db", "migrations"))
+	if err != nil {
+		return err
+	}
+
+	migration := resource.Migration + "_create_table_" + resource.Snake
+	outputs := []struct {
+		template string
+		path     string
+	}{
+		{"domain.go.tmpl", "model/domain/" + resource.Snake + ".go"},
+		{"create_request.go.tmpl", "model/web/" + resource.Snake + "_create_request.go"},
+		{"update_request.go.tmpl", "model/web/" + resource.Snake + "_update_request.go"},
+		{"response.go.tmpl", "model/web/" + resource.Snake + "_response.go"},
+		{"helper_model.go.tmpl", "helper/" + resource.Snake + "_model.go"},
+		{"repository.go.tmpl", "repository/" + resource.Snake + "_repository.go"},
+		{"repository_impl.go.tmpl", "repository/" + resource.Snake + "_repository_impl.go"},
+		{"service.go.tmpl", "service/" + resource.Snake + "_service.go"},
+		{"service_impl.go.tmpl", "service/" + resource.Snake + "_service_impl.go"},
+		{"controller.go.tmpl", "controller/" + resource.Snake + "_controller.go"},
+		{"controller_impl.go.tmpl", "controller/" + resource.Snake + "_controller_impl.go"},
+		{"router.go.tmpl", "config/" + resource.Snake + "_router.go"},
+		{"controller_test.go.tmpl", "test/" + resource.Snake + "_controller_test.go"},
+		{"migration.up.sql.tmpl", "db/migrations/" + migration + ".up.sql"},
+		{"migration.down.sql.tmpl", "db/migrations/" + migration + ".down.sql"},
+	}
+
+	if !force {
+		for _, output := range outputs {
+			if _, err := os.Stat(filepath.Join(dir, output.path)); err == nil {
+				return fmt.Errorf("%s already exists, pass -force to overwrite it", output.path)
+			}
+		}
+	}
+
+	parsed, err := template.ParseFS(templates, "templates/*.tmpl")
+	if err != nil {
+		return err
+	}
+
+	specPath := filepath.Join(dir, "apispec.json")
+	spec, err := os.ReadFile(specPath)
+	if err != nil {
+		return err
+	}
+	spec, err = addToApiSpec(spec, resource)
+	if err != nil {
+		return err
+	}
+
+	for _, output := range outputs {
+		buffer := &bytes.Buffer{}
+		if err := parsed.ExecuteTemplate(buffer, output.template, resource); err != nil {
+			return err
+		}
+
+		content := buffer.Bytes()
+		if strings.HasSuffix(output.path, ".go") {
+			content, err = format.Source(content)
+			if err != nil {
+				return fmt.Errorf("%s: %w", output.path, err)
+			}
+		}
+
+		path := filepath.Join(dir, output.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+		fmt.Println("created", output.path)
+	}
+
+	if err := os.WriteFile(specPath, spec, 0644); err != nil {
+		return err
+	}
+	fmt.Println("updated apispec.json")
+
+	fmt.Printf(`
+Wire the resource in main.go after the router is created:
+
+	%[1]sRepository := repository.New%[2]sRepository()
+	%[1]sService := service.New%[2]sService(%[1]sRepository, db, validate)
+	config.Register%[2]sRoutes(config.Routes{Router: router, Chain: chains.Protected.Append(chains.Metered...)}, controller.New%[2]sController(%[1]sService))
+`, resource.Var, resource.Name)
+	return nil
+}
+
+func readModule(dir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module ")), nil
+		}
+	}
+	return "", fmt.Errorf("go.mod has no module directive")
+}
+
+// nextMigration numbers the new migration after the highest one in dir, keeping the zero padding.
+func nextMigration(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	var numbers []int
+	for _, entry := range entries {
+		if match := migrationNumber.FindStringSubmatch(entry.Name()); match != nil {
+			number, _ := strconv.Atoi(match[1])
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+
+	next := 1
+	if len(numbers) > 0 {
+		next = numbers[len(numbers)-1] + 1
+	}
+	return fmt.Sprintf("%06d", next), nil
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type Field struct {
+	Name     string
+	Column   string
+	Json     string
+	Type     string
+	Rules    string
+	SqlType  string
+	SpecType string
+	// GoSample and JsonSample are values satisfying Rules, used by the generated tests.
+	GoSample   string
+	JsonSample string
+}
+
+type Resource struct {
+	Module string
+	Name   string
+	Var    string
+	Snake  string
+	Title  string
+	Plural string
+	Path   string
+	// Scope prefixes the read and write scopes the routes require, like categories:read.
+	Scope     string
+	IdParam   string
+	Migration string
+	Fields    []Field
+}
+
+var fieldTypes = map[string]struct {
+	sqlType  string
+	specType string
+}{
+	"string":  {"VARCHAR(255)", "string"},
+	"int":     {"INT", "number"},
+	"int64":   {"BIGINT", "number"},
+	"float64": {"DOUBLE", "number"},
+	"bool":    {"BOOLEAN", "boolean"},
+}
+
+func NewResource(module string, name string, fieldSpecs []string) (Resource, error) {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return Resource{}, fmt.Errorf("resource name %q has no letters", name)
+	}
+
+	resource := Resource{
+		Module:  module,
+		Name:    pascal(words),
+		Var:     camel(words),
+		Snake:   strings.Join(words, "_"),
+		Title:   strings.Join(words, " "),
+		IdParam: camel(words) + "Id",
+	}
+	plural := append(append([]string{}, words[:len(words)-1]...), pluralize(words[len(words)-1]))
+	resource.Plural = strings.Join(plural, " ")
+	resource.Path = "/" + strings.Join(plural, "-")
+	resource.Scope = strings.Join(plural, "-")
+
+	if len(fieldSpecs) == 0 {
+		return Resource{}, fmt.Errorf("at least one field is required")
+	}
+	seen := map[string]bool{"id": true, "tenant_id": true}
+	for _, fieldSpec := range fieldSpecs {
+		field, err := parseField(fieldSpec)
+		if err != nil {
+			return Resource{}, err
+		}
+		if seen[field.Column] {
+			return Resource{}, fmt.Errorf("field %q is declared twice or clashes with a built-in column", field.Column)
+		}
+		seen[field.Column] = true
+		resource.Fields = append(resource.Fields, field)
+	}
+	return resource, nil
+}
+
+// HasRequired reports whether an empty create request fails validation, which the generated tests rely on.
+func (resource Resource) HasRequired() bool {
+	for _, field := range resource.Fields {
+		if field.Required() {
+			return true
+		}
+	}
+	return false
+}
+
+// FirstString is the field the generated tests compare after a round trip, if any.
+func (resource Resource) FirstString() *Field {
+	for i := range resource.Fields {
+		if resource.Fields[i].Type == "string" {
+			return &resource.Fields[i]
+		}
+	}
+	return nil
+}
+
+func (resource Resource) SampleJson() string {
+	members := make([]string, len(resource.Fields))
+	for i, field := range resource.Fields {
+		members[i] = fmt.Sprintf("%q: %s", field.Json, field.JsonSample)
+	}
+	return "{" + strings.Join(members, ", ") + "}"
+}
+
+func (field Field) Required() bool {
+	for _, rule := range strings.Split(field.Rules, ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseField reads name:type[:validate rules], for example title:string:required,max=200.
+func parseField(spec string) (Field, error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) < 2 {
+		return Field{}, fmt.Errorf("field %q must look like name:type[:rules]", spec)
+	}
+
+	words := splitWords(parts[0])
+	if len(words) == 0 {
+		return Field{}, fmt.Errorf("field %q has no name", spec)
+	}
+	fieldType, ok := fieldTypes[parts[1]]
+	if !ok {
+		return Field{}, fmt.Errorf("field %q has unsupported type %q, use string, int, int64, float64 or bool", spec, parts[1])
+	}
+
+	field := Field{
+		Name:     pascal(words),
+		Column:   strings.Join(words, "_"),
+		Json:     strings.Join(words, "_"),
+		Type:     parts[1],
+		SqlType:  fieldType.sqlType,
+		SpecType: fieldType.specType,
+	}
+	if len(parts) == 3 {
+		field.Rules = parts[2]
+	}
+
+	rules := map[string]string{}
+	for _, rule := range strings.Split(field.Rules, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		rules[key] = value
+	}
+	if max, ok := rules["max"]; ok && field.Type == "string" {
+		if _, err := strconv.Atoi(max); err == nil {
+			field.SqlType = "VARCHAR(" + max + ")"
+		}
+	}
+
+	switch field.Type {
+	case "string":
+		sample := "Sample " + strings.Join(words, " ")
+		if _, ok := rules["email"]; ok {
+			sample = "sample@example.com"
+		}
+		if max, err := strconv.Atoi(rules["max"]); err == nil && len(sample) > max {
+			sample = strings.Repeat("a", max)
+		}
+		field.GoSample = strconv.Quote(sample)
+		field.JsonSample = strconv.Quote(sample)
+	case "bool":
+		field.GoSample = "true"
+		field.JsonSample = "true"
+	default:
+		sample := "1"
+		if min, ok := rules["min"]; ok {
+			sample = min
+		}
+		field.GoSample = sample
+		field.JsonSample = sample
+	}
+	return field, nil
+}
+
+// splitWords breaks snake_case, kebab-case, camelCase and PascalCase names into lower case words.
+func splitWords(name string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+		case unicode.IsUpper(r):
+			if len(current) > 0 && (i+1 < len(runes) && unicode.IsLower(runes[i+1]) || unicode.IsLower(runes[i-1])) {
+				words = append(words, string(current))
+				current = nil
+			}
+			current = append(current, unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			current = append(current, r)
+		}
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+func pascal(words []string) string {
+	var builder strings.Builder
+	for _, word := range words {
+		if word == "id" {
+			builder.WriteString("Id")
+			continue
+		}
+		builder.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return builder.String()
+}
+
+func camel(words []string) string {
+	name := pascal(words)
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func pluralize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+	}{
+		{"supplier", []string{"supplier"}},
+		{"purchase_order", []string{"purchase", "order"}},
+		{"purchase-order", []string{"purchase", "order"}},
+		{"purchase order", []string{"purchase", "order"}},
+		{"PurchaseOrder", []string{"purchase", "order"}},
+		{"purchaseOrder", []string{"purchase", "order"}},
+		{"HTTPServer", []string{"http", "server"}},
+		{"userID", []string{"user", "id"}},
+		{"order2_line", []string{"order2", "line"}},
+		{"__", nil},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.words, splitWords(test.name), test.name)
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		word   string
+		plural string
+	}{
+		{"supplier", "suppliers"},
+		{"category", "categories"},
+		{"key", "keys"},
+		{"box", "boxes"},
+		{"address", "addresses"},
+		{"batch", "batches"},
+		{"dish", "dishes"},
+		{"y", "ys"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.plural, pluralize(test.word), test.word)
+	}
+}
+
+func TestNextMigration(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		next  string
+	}{
+		{"missing directory", nil, "000001"},
+		{"no migrations", []string{"README.md"}, "000001"},
+		{"after the highest", []string{"000001_a.up.sql", "000001_a.down.sql", "000009_b.up.sql", "000003_c.up.sql", "README.md"}, "000010"},
+		{"past the padding", []string{"999999_a.up.sql"}, "1000000"},
+	}
+	for _, test := range tests {
+		dir := filepath.Join(t.TempDir(), "migrations")
+		for _, file := range test.files {
+			assert.Nil(t, os.MkdirAll(dir, 0755))
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, file), nil, 0644))
+		}
+
+		next, err := nextMigration(dir)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.next, next, test.name)
+	}
+}
+
+const emptySpec = "{\n\t\"openapi\": \"3.0.3\",\n\t\"paths\": {\n\t},\n\t\"components\": {\n\t\t\"schemas\": {\n\t\t}\n\t}\n}\n"
+
+func TestAddToApiSpec(t *testing.T) {
+	supplier, err := NewResource("example.com/api", "supplier", []string{"name:string:required", "rating:int"})
+	assert.Nil(t, err)
+	purchaseOrder, err := NewResource("example.com/api", "purchase_order", []string{"total:float64"})
+	assert.Nil(t, err)
+	withPurchaseOrder, err := addToApiSpec([]byte(emptySpec), purchaseOrder)
+	assert.Nil(t, err)
+	withSupplier, err := addToApiSpec([]byte(emptySpec), supplier)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name  string
+		spec  string
+		paths int
+		err   string
+	}{
+		{"empty spec", emptySpec, 2, ""},
+		{"spec with another resource", string(withPurchaseOrder), 4, ""},
+		{"documented already", string(withSupplier), 0, "apispec.json already documents /suppliers"},
+		{"invalid json", "{", 0, "apispec.json is not valid JSON: unexpected end of JSON input"},
+		{"no schemas object", "{\n\t\"paths\": {\n\t}\n}\n", 0, `apispec.json has no "schemas" object`},
+	}
+	for _, test := range tests {
+		spec, err := addToApiSpec([]byte(test.spec), supplier)
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, test.name)
+			continue
+		}
+		assert.Nil(t, err, test.name)
+
+		var parsed struct {
+			Paths      map[string]map[string]interface{} `json:"paths"`
+			Components struct {
+				Schemas map[string]struct {
+					Properties map[string]interface{} `json:"properties"`
+				} `json:"schemas"`
+			} `json:"components"`
+		}
+		assert.Nil(t, json.Unmarshal(spec, &parsed), test.name)
+		assert.Len(t, parsed.Paths, test.paths, test.name)
+		assert.Contains(t, parsed.Paths["/suppliers"], "post", test.name)
+		assert.Contains(t, parsed.Paths["/suppliers/{supplierId}"], "delete", test.name)
+		assert.Contains(t, parsed.Components.Schemas["Supplier"].Properties, "id", test.name)
+		assert.Contains(t, parsed.Components.Schemas["CreateOrUpdateSupplier"].Properties, "rating", test.name)
+	}
+}
+
+// TestRunGeneratesBuildableResource scaffolds a resource into a copy of the repository and vets it.
+func TestRunGeneratesBuildableResource(t *testing.T) {
+	if testing.Short() {
+		t.Skip("copies and vets the whole repository")
+	}
+	dir := t.TempDir()
+	assert.Nil(t, copyRepository("../..", dir))
+
+	assert.Nil(t, run(dir, false, "supplier", []string{"name:string:required,max=200", "rating:int:min=0,max=5"}))
+	assert.FileExists(t, filepath.Join(dir, "service", "supplier_service_impl.go"))
+	assert.FileExists(t, filepath.Join(dir, "test", "supplier_controller_test.go"))
+	assert.EqualError(t, run(dir, false, "supplier", []string{"name:string"}), "model/domain/supplier.go already exists, pass -force to overwrite it")
+
+	vet := exec.Command("go", "vet", "./...")
+	vet.Dir = dir
+	output, err := vet.CombinedOutput()
+	assert.Nil(t, err, string(output))
+}
+
+func copyRepository(from string, to string) error {
+	return filepath.WalkDir(from, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(to, relative), 0755)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(to, relative), content, 0644)
+	})
+}
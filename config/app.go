@@ -0,0 +1,388 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"gopkg.in/yaml.v3"
+)
+
+// AppConfig holds the settings needed to start the server. LoadAppConfig layers them:
+// the defaults, then the YAML or JSON file, then environment variables, then flags.
+type AppConfig struct {
+	Database DatabaseConfig `yaml:"database"`
+	Server   ServerConfig   `yaml:"server"`
+	Tls      TlsSettings    `yaml:"tls"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Cors     CorsSettings   `yaml:"cors"`
+	Limits   LimitsConfig   `yaml:"limits"`
+	Log      LogConfig      `yaml:"log"`
+	Errors   ErrorsConfig   `yaml:"errors"`
+}
+
+// DatabaseConfig SqlSlowThreshold of 0 disables the slow query log.
+type DatabaseConfig struct {
+	Dsn              string        `yaml:"dsn"`
+	MaxOpenConns     int           `yaml:"max_open_conns"`
+	MaxIdleConns     int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime  time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime  time.Duration `yaml:"conn_max_idle_time"`
+	SqlSlowThreshold time.Duration `yaml:"sql_slow_threshold"`
+	SqlLogArgs       bool          `yaml:"sql_log_args"`
+	SqlRedactColumns string        `yaml:"sql_redact_columns"`
+}
+
+// ServerConfig timeouts of 0 mean no timeout.
+type ServerConfig struct {
+	Addr              string        `yaml:"addr"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+}
+
+// AuthConfig keeps the list settings in the format of their environment variables,
+// see NewApiKeyTenants and NewHmacKeys.
+type AuthConfig struct {
+	ApiKeyTenants      string        `yaml:"api_key_tenants"`
+	ApiKeyCacheTtl     time.Duration `yaml:"api_key_cache_ttl"`
+	ApiKeyCacheSize    int           `yaml:"api_key_cache_size"`
+	HmacKeys           string        `yaml:"hmac_keys"`
+	HmacClockSkew      time.Duration `yaml:"hmac_clock_skew"`
+	HmacNonceCacheSize int           `yaml:"hmac_nonce_cache_size"`
+	JwtSecret          string        `yaml:"jwt_secret"`
+	JwtJwksFile        string        `yaml:"jwt_jwks_file"`
+	JwtIssuer          string        `yaml:"jwt_issuer"`
+	JwtAudience        string        `yaml:"jwt_audience"`
+	JwtClockSkew       time.Duration `yaml:"jwt_clock_skew"`
+	JwtTenantClaim     string        `yaml:"jwt_tenant_claim"`
+	OauthSigningKey    string        `yaml:"oauth_signing_key"`
+	OauthTokenTtl      time.Duration `yaml:"oauth_token_ttl"`
+}
+
+// CorsSettings keeps the lists comma separated like AuthConfig, see NewCorsConfig.
+type CorsSettings struct {
+	AllowedOrigins   string        `yaml:"allowed_origins"`
+	AllowedMethods   string        `yaml:"allowed_methods"`
+	AllowedHeaders   string        `yaml:"allowed_headers"`
+	ExposedHeaders   string        `yaml:"exposed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+// LimitsConfig names the JSON files of NewRateLimits and NewUsageQuotas.
+type LimitsConfig struct {
+	RateLimitsFile  string `yaml:"rate_limits_file"`
+	UsageQuotasFile string `yaml:"usage_quotas_file"`
+}
+
+type LogConfig struct {
+	Level                  string  `yaml:"level"`
+	Format                 string  `yaml:"format"`
+	AccessLogSampleRate    float64 `yaml:"access_log_sample_rate"`
+	AccessLogRedactHeaders string  `yaml:"access_log_redact_headers"`
+}
+
+type ErrorsConfig struct {
+	// Format "problem" answers every error as RFC 7807 problem+json, "envelope" keeps the WebResponse
+	// body unless the client accepts application/problem+json.
+	Format string `yaml:"format"`
+	// Debug sends panic values and stacks to clients and is meant for local development.
+	Debug bool `yaml:"debug"`
+}
+
+// MaxApiKeyCacheTtl bounds how long a revoked key keeps working on the instances that did not revoke it.
+const MaxApiKeyCacheTtl = 5 * time.Minute
+
+func DefaultAppConfig() AppConfig {
+	return AppConfig{
+		Database: DatabaseConfig{
+			Dsn:              "root:@tcp(localhost:3306)/belajar_golang_restful_api?parseTime=true",
+			MaxOpenConns:     20,
+			MaxIdleConns:     5,
+			ConnMaxLifetime:  60 * time.Minute,
+			ConnMaxIdleTime:  10 * time.Minute,
+			SqlSlowThreshold: 200 * time.Millisecond,
+		},
+		Server: ServerConfig{
+			Addr:              "localhost:3000",
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+		},
+		Auth: AuthConfig{
+			ApiKeyCacheTtl:     30 * time.Second,
+			ApiKeyCacheSize:    10000,
+			HmacClockSkew:      5 * time.Minute,
+			HmacNonceCacheSize: 100000,
+			JwtClockSkew:       30 * time.Second,
+			OauthTokenTtl:      15 * time.Minute,
+		},
+		Cors: CorsSettings{
+			AllowedMethods: "GET,POST,PUT,DELETE",
+			AllowedHeaders: "Content-Type,Accept,Authorization,X-API-Key",
+			ExposedHeaders: "RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After,X-Request-ID",
+			MaxAge:         10 * time.Minute,
+		},
+		Log: LogConfig{
+			Level:               "info",
+			Format:              "json",
+			AccessLogSampleRate: 1,
+		},
+		Errors: ErrorsConfig{
+			Format: "envelope",
+		},
+	}
+}
+
+// bindFlags registers a flag for every setting. The environment variable of a setting is its flag
+// name in upper case with dashes turned into underscores, -db-dsn being read from DB_DSN.
+func (appConfig *AppConfig) bindFlags(flags *flag.FlagSet) {
+	database, server, tlsSettings, auth := &appConfig.Database, &appConfig.Server, &appConfig.Tls, &appConfig.Auth
+	cors, limits, log, errorsConfig := &appConfig.Cors, &appConfig.Limits, &appConfig.Log, &appConfig.Errors
+	flags.StringVar(&database.Dsn, "db-dsn", database.Dsn, "MySQL data source name")
+	flags.IntVar(&database.MaxOpenConns, "db-max-open-conns", database.MaxOpenConns, "maximum open connections, 0 for unlimited")
+	flags.IntVar(&database.MaxIdleConns, "db-max-idle-conns", database.MaxIdleConns, "maximum idle connections")
+	flags.DurationVar(&database.ConnMaxLifetime, "db-conn-max-lifetime", database.ConnMaxLifetime, "maximum lifetime of a connection")
+	flags.DurationVar(&database.ConnMaxIdleTime, "db-conn-max-idle-time", database.ConnMaxIdleTime, "maximum idle time of a connection")
+	flags.DurationVar(&database.SqlSlowThreshold, "sql-slow-threshold", database.SqlSlowThreshold, "duration from which a query is logged as slow, 0 disables it")
+	flags.BoolVar(&database.SqlLogArgs, "sql-log-args", database.SqlLogArgs, "log query arguments")
+	flags.StringVar(&database.SqlRedactColumns, "sql-redact-columns", database.SqlRedactColumns, "comma separated columns redacted on top of the defaults")
+
+	flags.StringVar(&server.Addr, "server-addr", server.Addr, "host:port to listen on")
+	flags.DurationVar(&server.ReadHeaderTimeout, "server-read-header-timeout", server.ReadHeaderTimeout, "time allowed to read request headers")
+	flags.DurationVar(&server.ReadTimeout, "server-read-timeout", server.ReadTimeout, "time allowed to read a whole request")
+	flags.DurationVar(&server.WriteTimeout, "server-write-timeout", server.WriteTimeout, "time allowed to write a response")
+	flags.DurationVar(&server.IdleTimeout, "server-idle-timeout", server.IdleTimeout, "time a keep-alive connection may stay idle")
+
+	flags.StringVar(&tlsSettings.CertFile, "tls-cert-file", tlsSettings.CertFile, "PEM certificate, serves HTTPS when set")
+	flags.StringVar(&tlsSettings.KeyFile, "tls-key-file", tlsSettings.KeyFile, "PEM private key of the certificate")
+	flags.StringVar(&tlsSettings.ClientCaFile, "tls-client-ca-file", tlsSettings.ClientCaFile, "PEM bundle verifying client certificates")
+	flags.BoolVar(&tlsSettings.RequireClientCert, "tls-require-client-cert", tlsSettings.RequireClientCert, "refuse connections without a client certificate")
+	flags.StringVar(&tlsSettings.ClientCertIdentitiesFile, "client-cert-identities-file", tlsSettings.ClientCertIdentitiesFile, "JSON file mapping client certificate names to tenants and scopes")
+
+	flags.StringVar(&auth.ApiKeyTenants, "api-key-tenants", auth.ApiKeyTenants, "static API keys as comma separated key:tenant pairs")
+	flags.DurationVar(&auth.ApiKeyCacheTtl, "api-key-cache-ttl", auth.ApiKeyCacheTtl, "how long a database API key lookup is cached")
+	flags.IntVar(&auth.ApiKeyCacheSize, "api-key-cache-size", auth.ApiKeyCacheSize, "maximum cached API keys")
+	flags.StringVar(&auth.HmacKeys, "hmac-keys", auth.HmacKeys, "signing keys as comma separated keyId:secret:tenant:scopes entries")
+	flags.DurationVar(&auth.HmacClockSkew, "hmac-clock-skew", auth.HmacClockSkew, "accepted age of a signed request")
+	flags.IntVar(&auth.HmacNonceCacheSize, "hmac-nonce-cache-size", auth.HmacNonceCacheSize, "maximum remembered request nonces, shared evenly between the signing keys")
+	flags.StringVar(&auth.JwtSecret, "jwt-secret", auth.JwtSecret, "HMAC secret of bearer tokens")
+	flags.StringVar(&auth.JwtJwksFile, "jwt-jwks-file", auth.JwtJwksFile, "JWKS file with the public keys of bearer tokens")
+	flags.StringVar(&auth.JwtIssuer, "jwt-issuer", auth.JwtIssuer, "required iss of bearer tokens")
+	flags.StringVar(&auth.JwtAudience, "jwt-audience", auth.JwtAudience, "required aud of bearer tokens")
+	flags.DurationVar(&auth.JwtClockSkew, "jwt-clock-skew", auth.JwtClockSkew, "leeway when checking token times")
+	flags.StringVar(&auth.JwtTenantClaim, "jwt-tenant-claim", auth.JwtTenantClaim, "claim holding the tenant of bearer tokens")
+	flags.StringVar(&auth.OauthSigningKey, "oauth-signing-key", auth.OauthSigningKey, "key signing issued access tokens, random when empty")
+	flags.DurationVar(&auth.OauthTokenTtl, "oauth-token-ttl", auth.OauthTokenTtl, "lifetime of issued access tokens")
+
+	flags.StringVar(&cors.AllowedOrigins, "cors-allowed-origins", cors.AllowedOrigins, "comma separated origins allowed to call the api, CORS is off when empty")
+	flags.StringVar(&cors.AllowedMethods, "cors-allowed-methods", cors.AllowedMethods, "comma separated methods allowed in cross origin requests")
+	flags.StringVar(&cors.AllowedHeaders, "cors-allowed-headers", cors.AllowedHeaders, "comma separated headers allowed in cross origin requests")
+	flags.StringVar(&cors.ExposedHeaders, "cors-exposed-headers", cors.ExposedHeaders, "comma separated response headers exposed to cross origin callers")
+	flags.BoolVar(&cors.AllowCredentials, "cors-allow-credentials", cors.AllowCredentials, "allow cross origin requests with credentials")
+	flags.DurationVar(&cors.MaxAge, "cors-max-age", cors.MaxAge, "how long a preflight answer may be cached")
+
+	flags.StringVar(&limits.RateLimitsFile, "rate-limits-file", limits.RateLimitsFile, "JSON file with the rate limits")
+	flags.StringVar(&limits.UsageQuotasFile, "usage-quotas-file", limits.UsageQuotasFile, "JSON file with the usage quotas, usage is not limited without it")
+
+	flags.StringVar(&log.Level, "log-level", log.Level, "debug, info, warn or error")
+	flags.StringVar(&log.Format, "log-format", log.Format, "json or text")
+	flags.Float64Var(&log.AccessLogSampleRate, "access-log-sample-rate", log.AccessLogSampleRate, "share of successful requests logged")
+	flags.StringVar(&log.AccessLogRedactHeaders, "access-log-redact-headers", log.AccessLogRedactHeaders, "comma separated headers redacted on top of the defaults")
+
+	flags.StringVar(&errorsConfig.Format, "error-format", errorsConfig.Format, "envelope or problem")
+	flags.BoolVar(&errorsConfig.Debug, "app-debug", errorsConfig.Debug, "send panic values and stacks to clients")
+}
+
+// LoadAppConfig reads the file named by -config or CONFIG_FILE, then the environment, then args,
+// and validates the result. Tools with flags of their own pass nil args.
+func LoadAppConfig(args []string) (AppConfig, error) {
+	// the flags are parsed first to find the file, but applied last
+	flags := flag.NewFlagSet("golang-restful-api", flag.ContinueOnError)
+	parsed := DefaultAppConfig()
+	parsed.bindFlags(flags)
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or JSON configuration file")
+	if err := flags.Parse(args); err != nil {
+		return AppConfig{}, err
+	}
+
+	appConfig := DefaultAppConfig()
+	if *file != "" {
+		if err := appConfig.readFile(*file); err != nil {
+			return AppConfig{}, err
+		}
+	}
+
+	layer := flag.NewFlagSet("", flag.ContinueOnError)
+	appConfig.bindFlags(layer)
+	var err error
+	layer.VisitAll(func(setting *flag.Flag) {
+		name := strings.ToUpper(strings.ReplaceAll(setting.Name, "-", "_"))
+		if value := os.Getenv(name); value != "" && err == nil {
+			if setErr := layer.Set(setting.Name, value); setErr != nil {
+				err = fmt.Errorf("%s: %w", name, setErr)
+			}
+		}
+	})
+	flags.Visit(func(setting *flag.Flag) {
+		if setting.Name != "config" && err == nil {
+			err = layer.Set(setting.Name, setting.Value.String())
+		}
+	})
+	if err != nil {
+		return AppConfig{}, err
+	}
+
+	return appConfig, appConfig.Validate()
+}
+
+func (appConfig *AppConfig) readFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// JSON is valid YAML, so one decoder reads both
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(appConfig); err != nil && err != io.EOF {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (appConfig AppConfig) Validate() error {
+	var errs []error
+	invalid := func(name string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(name+": "+format, args...))
+	}
+
+	database := appConfig.Database
+	if _, err := mysql.ParseDSN(database.Dsn); err != nil {
+		invalid("database.dsn", "%v", err)
+	}
+	if database.MaxOpenConns < 0 {
+		invalid("database.max_open_conns", "must not be negative")
+	}
+	if database.MaxIdleConns < 0 {
+		invalid("database.max_idle_conns", "must not be negative")
+	}
+	if database.MaxOpenConns > 0 && database.MaxIdleConns > database.MaxOpenConns {
+		invalid("database.max_idle_conns", "must not exceed max_open_conns %d", database.MaxOpenConns)
+	}
+
+	server := appConfig.Server
+	if _, _, err := net.SplitHostPort(server.Addr); err != nil {
+		invalid("server.addr", "must look like host:port: %v", err)
+	}
+
+	auth := appConfig.Auth
+	if _, err := parseApiKeyTenants(auth.ApiKeyTenants); err != nil {
+		invalid("auth.api_key_tenants", "%v", err)
+	}
+	if auth.ApiKeyCacheTtl > MaxApiKeyCacheTtl {
+		invalid("auth.api_key_cache_ttl", "must not exceed %s, other instances accept a revoked key that long", MaxApiKeyCacheTtl)
+	}
+	if auth.ApiKeyCacheSize <= 0 {
+		invalid("auth.api_key_cache_size", "must be positive")
+	}
+	if _, err := parseHmacKeys(auth.HmacKeys); err != nil {
+		invalid("auth.hmac_keys", "%v", err)
+	}
+	if auth.HmacKeys != "" && auth.HmacClockSkew <= 0 {
+		invalid("auth.hmac_clock_skew", "must be positive")
+	}
+	if auth.HmacNonceCacheSize <= 0 {
+		invalid("auth.hmac_nonce_cache_size", "must be positive")
+	}
+	if auth.JwtJwksFile != "" {
+		if _, err := middleware.LoadJwks(auth.JwtJwksFile); err != nil {
+			invalid("auth.jwt_jwks_file", "%v", err)
+		}
+	}
+	if auth.OauthTokenTtl <= 0 {
+		invalid("auth.oauth_token_ttl", "must be positive")
+	}
+
+	tlsSettings := appConfig.Tls
+	if tlsSettings.Enabled() {
+		if _, err := LoadTlsConfig(tlsSettings); err != nil {
+			invalid("tls", "%v", err)
+		}
+	} else if tlsSettings.ClientCaFile != "" || tlsSettings.RequireClientCert {
+		invalid("tls.cert_file", "must be set to verify client certificates")
+	}
+	if _, err := loadClientCertIdentities(tlsSettings.ClientCertIdentitiesFile); err != nil {
+		invalid("tls.client_cert_identities_file", "%v", err)
+	}
+
+	cors := appConfig.Cors
+	for _, origin := range splitList(cors.AllowedOrigins) {
+		if origin == "*" {
+			if cors.AllowCredentials {
+				invalid("cors.allow_credentials", "cannot be combined with the * origin, list the trusted origins in cors.allowed_origins")
+			}
+		} else if err := checkOrigin(origin); err != nil {
+			invalid("cors.allowed_origins", "%v", err)
+		}
+	}
+
+	limits := appConfig.Limits
+	if _, err := loadRateLimits(limits.RateLimitsFile); err != nil {
+		invalid("limits.rate_limits_file", "%v", err)
+	}
+	if _, err := loadUsageQuotas(limits.UsageQuotasFile); err != nil {
+		invalid("limits.usage_quotas_file", "%v", err)
+	}
+
+	log := appConfig.Log
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(log.Level)); err != nil {
+		invalid("log.level", "must be debug, info, warn or error")
+	}
+	if log.Format != "json" && log.Format != "text" {
+		invalid("log.format", "must be json or text")
+	}
+	if log.AccessLogSampleRate < 0 || log.AccessLogSampleRate > 1 {
+		invalid("log.access_log_sample_rate", "must be between 0 and 1")
+	}
+	if format := appConfig.Errors.Format; format != "envelope" && format != "problem" {
+		invalid("errors.format", "must be envelope or problem")
+	}
+
+	durations := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"database.conn_max_lifetime", database.ConnMaxLifetime},
+		{"database.conn_max_idle_time", database.ConnMaxIdleTime},
+		{"database.sql_slow_threshold", database.SqlSlowThreshold},
+		{"server.read_header_timeout", server.ReadHeaderTimeout},
+		{"server.read_timeout", server.ReadTimeout},
+		{"server.write_timeout", server.WriteTimeout},
+		{"server.idle_timeout", server.IdleTimeout},
+		{"auth.api_key_cache_ttl", auth.ApiKeyCacheTtl},
+		{"auth.jwt_clock_skew", auth.JwtClockSkew},
+		{"cors.max_age", cors.MaxAge},
+	}
+	for _, setting := range durations {
+		if setting.duration < 0 {
+			invalid(setting.name, "must not be negative")
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mrakhaf/golang-restful-api/middleware"
+)
+
+// NewCorsConfig splits the comma separated lists of cors. ok is false when no origin is allowed.
+// Credentials cannot be allowed for the "*" origin, as any website could then call the api as its user,
+// Validate refuses that combination.
+func NewCorsConfig(cors CorsSettings) (corsConfig middleware.CorsConfig, ok bool) {
+	corsConfig = middleware.CorsConfig{
+		AllowedOrigins:   splitList(cors.AllowedOrigins),
+		AllowedMethods:   splitList(cors.AllowedMethods),
+		AllowedHeaders:   splitList(cors.AllowedHeaders),
+		ExposedHeaders:   splitList(cors.ExposedHeaders),
+		AllowCredentials: cors.AllowCredentials,
+		MaxAge:           cors.MaxAge,
+	}
+	return corsConfig, len(corsConfig.AllowedOrigins) > 0
+}
+
+// checkOrigin accepts origins like "https://admin.example.com" or "https://*.example.com".
+func checkOrigin(origin string) error {
+	parsed, err := url.Parse(origin)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+		parsed.User != nil || parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("%q is not an origin like https://admin.example.com", origin)
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
@@ -2,19 +2,32 @@ package config
 
 import (
 	"database/sql"
-	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/repository"
 )
 
-func NewDB() *sql.DB {
-	db, err := sql.Open("mysql", "root:@tcp(localhost:3306)/belajar_golang_restful_api")
+func NewDB(database DatabaseConfig) *sql.DB {
+	mysqlConfig, err := mysql.ParseDSN(database.Dsn)
 	helper.PanicIfError(err)
+	connector, err := mysql.NewConnector(mysqlConfig)
+	helper.PanicIfError(err)
+	db := sql.OpenDB(repository.NewLoggingConnector(connector, NewSqlLogConfig(database)))
 
-	db.SetMaxIdleConns(5)
-	db.SetMaxOpenConns(20)
-	db.SetConnMaxLifetime(60 * time.Minute)
-	db.SetConnMaxIdleTime(10 * time.Minute)
+	db.SetMaxIdleConns(database.MaxIdleConns)
+	db.SetMaxOpenConns(database.MaxOpenConns)
+	db.SetConnMaxLifetime(database.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(database.ConnMaxIdleTime)
 
 	return db
 }
+
+// NewSqlLogConfig redacts the comma separated SqlRedactColumns on top of repository.DefaultRedactColumns.
+func NewSqlLogConfig(database DatabaseConfig) repository.SqlLogConfig {
+	return repository.SqlLogConfig{
+		SlowThreshold: database.SqlSlowThreshold,
+		LogArgs:       database.SqlLogArgs,
+		RedactColumns: append(append([]string{}, repository.DefaultRedactColumns...), splitList(database.SqlRedactColumns)...),
+	}
+}
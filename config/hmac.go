@@ -0,0 +1,33 @@
+package config
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+)
+
+// NewHmacKeys returns the shared secrets accepted for signed requests. auth.HmacKeys holds comma separated
+// keyId:secret:tenant:scopes entries, scopes being space separated; without it signing is disabled.
+func NewHmacKeys(auth AuthConfig) map[string]middleware.HmacKey {
+	keys, err := parseHmacKeys(auth.HmacKeys)
+	helper.PanicIfError(err)
+	return keys
+}
+
+func parseHmacKeys(value string) (map[string]middleware.HmacKey, error) {
+	keys := map[string]middleware.HmacKey{}
+	if value == "" {
+		return keys, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 4)
+		if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, errors.New("HMAC_KEYS entries must look like keyId:secret:tenant:scopes")
+		}
+		keys[parts[0]] = middleware.HmacKey{Secret: []byte(parts[1]), TenantId: parts[2], Scopes: strings.Fields(parts[3])}
+	}
+	return keys, nil
+}
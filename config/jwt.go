@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+)
+
+// NewJwtConfig returns the bearer token settings of auth. ok is false when neither a secret nor a JWKS file is set.
+func NewJwtConfig(auth AuthConfig) (jwtConfig middleware.JwtConfig, ok bool) {
+	jwtConfig = middleware.JwtConfig{
+		Secret:      []byte(auth.JwtSecret),
+		Issuer:      auth.JwtIssuer,
+		Audience:    auth.JwtAudience,
+		ClockSkew:   auth.JwtClockSkew,
+		TenantClaim: auth.JwtTenantClaim,
+	}
+
+	if auth.JwtJwksFile != "" {
+		keys, err := middleware.LoadJwks(auth.JwtJwksFile)
+		helper.PanicIfError(err)
+		jwtConfig.Keys = keys
+	}
+
+	return jwtConfig, len(jwtConfig.Secret) > 0 || len(jwtConfig.Keys) > 0
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+)
+
+// NewLogger writes to stderr in the log.format "json" or "text", dropping records below log.level:
+// "debug", "info", "warn" or "error".
+func NewLogger(log LogConfig) *slog.Logger {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(log.Level))
+	helper.PanicIfError(err)
+	options := &slog.HandlerOptions{Level: level}
+
+	if log.Format == "text" {
+		return slog.New(slog.NewTextHandler(os.Stderr, options))
+	}
+	return slog.New(slog.NewJSONHandler(os.Stderr, options))
+}
+
+// NewAccessLogConfig logs the log.access_log_sample_rate share of successful requests and redacts
+// the comma separated log.access_log_redact_headers on top of middleware.DefaultRedactHeaders.
+func NewAccessLogConfig(log LogConfig) middleware.AccessLogConfig {
+	return middleware.AccessLogConfig{
+		SampleRate:    log.AccessLogSampleRate,
+		RedactHeaders: append(append([]string{}, middleware.DefaultRedactHeaders...), splitList(log.AccessLogRedactHeaders)...),
+	}
+}
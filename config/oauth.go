@@ -0,0 +1,28 @@
+package config
+
+import (
+	"crypto/rand"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+const OauthIssuer = "golang-restful-api"
+
+// NewOauthTokenConfig uses auth.OauthSigningKey, or a random key when it is empty, which invalidates
+// issued tokens on restart and cannot be shared between instances.
+func NewOauthTokenConfig(auth AuthConfig) service.OauthTokenConfig {
+	tokenConfig := service.OauthTokenConfig{
+		SigningKey: []byte(auth.OauthSigningKey),
+		Issuer:     OauthIssuer,
+		TTL:        auth.OauthTokenTtl,
+	}
+
+	if len(tokenConfig.SigningKey) == 0 {
+		tokenConfig.SigningKey = make([]byte, 32)
+		_, err := rand.Read(tokenConfig.SigningKey)
+		helper.PanicIfError(err)
+	}
+
+	return tokenConfig
+}
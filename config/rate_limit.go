@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+)
+
+// DefaultRateLimits keep a single credential well below the 20 connections of the pool.
+var DefaultRateLimits = middleware.RateLimits{
+	Read:  middleware.RateLimit{Rate: 20, Burst: 40},
+	Write: middleware.RateLimit{Rate: 5, Burst: 10},
+}
+
+// DefaultClientIpRateLimits apply to every client IP in front of authentication, so requests with
+// missing or made up credentials cannot keep the pool busy with key lookups. They leave room for
+// several credentials sharing an address.
+var DefaultClientIpRateLimits = middleware.RateLimits{
+	Read:  middleware.RateLimit{Rate: 100, Burst: 200},
+	Write: middleware.RateLimit{Rate: 25, Burst: 50},
+}
+
+type RateLimitSettings struct {
+	Default  middleware.RateLimits            `json:"default"`
+	ClientIp middleware.RateLimits            `json:"client_ip"`
+	Keys     map[string]middleware.RateLimits `json:"keys"`
+}
+
+// NewRateLimits reads RateLimitsFile, a JSON object with the "default" limits, the "client_ip" limits
+// checked before authentication and per credential overrides under "keys", keyed like "api_key:12" or
+// "hmac:service-a". A zero burst defaults to the rate.
+func NewRateLimits(limits LimitsConfig) RateLimitSettings {
+	settings, err := loadRateLimits(limits.RateLimitsFile)
+	helper.PanicIfError(err)
+	return settings
+}
+
+func loadRateLimits(path string) (RateLimitSettings, error) {
+	settings := RateLimitSettings{Default: DefaultRateLimits, ClientIp: DefaultClientIpRateLimits, Keys: map[string]middleware.RateLimits{}}
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return RateLimitSettings{}, err
+		}
+		if err := json.Unmarshal(content, &settings); err != nil {
+			return RateLimitSettings{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	for key, limits := range settings.Keys {
+		settings.Keys[key] = withDefaultBurst(limits)
+	}
+	settings.Default = withDefaultBurst(settings.Default)
+	settings.ClientIp = withDefaultBurst(settings.ClientIp)
+	return settings, nil
+}
+
+func withDefaultBurst(limits middleware.RateLimits) middleware.RateLimits {
+	for _, limit := range []*middleware.RateLimit{&limits.Read, &limits.Write} {
+		if limit.Burst <= 0 {
+			limit.Burst = int(math.Max(1, math.Ceil(limit.Rate)))
+		}
+	}
+	return limits
+}
@@ -1,21 +1,65 @@
 package config
 
 import (
+	"net/http"
+
 	"github.com/julienschmidt/httprouter"
 	"github.com/mrakhaf/golang-restful-api/controller"
 	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/middleware"
 )
 
-func NewRouter(categoryController controller.CategoryController) *httprouter.Router {
+// NewRouter registers the routes behind chains.Protected unless they are marked public.
+func NewRouter(chains RouteChains, healthController controller.HealthController, categoryController controller.CategoryController, productController controller.ProductController, backupController controller.BackupController, apiKeyController controller.ApiKeyController, oauthController controller.OauthController, usageController controller.UsageController) *httprouter.Router {
 	router := httprouter.New()
+	public := Routes{Router: router, Chain: chains.Public}
+	protected := Routes{Router: router, Chain: chains.Protected}
+	metered := protected.With(chains.Metered...)
+
+	public.GET("/health", healthController.Check)
+	// the token endpoints authenticate the client themselves
+	public.POST("/oauth/token", oauthController.Token)
+	public.POST("/oauth/introspect", oauthController.Introspect)
+	public.POST("/oauth/revoke", oauthController.Revoke)
+
+	// every route declares the scopes its credential needs, see middleware.RequireScopes
+	metered.GET("/api/categories", middleware.RequireScopes(categoryController.FindAll, "categories:read"))
+	// httprouter cannot register a static segment next to a wildcard, so the export path is dispatched here
+	metered.GET("/api/categories/:categoryId", middleware.RequireScopes(func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		if params.ByName("categoryId") == "export" {
+			categoryController.Export(writer, request, params)
+			return
+		}
+		categoryController.FindById(writer, request, params)
+	}, "categories:read"))
+	metered.POST("/api/categories", middleware.RequireScopes(categoryController.Create, "categories:write"))
+	metered.POST("/api/categories/import", middleware.RequireScopes(categoryController.Import, "categories:write"))
+	metered.PUT("/api/categories/:categoryId", middleware.RequireScopes(categoryController.Update, "categories:write"))
+	metered.DELETE("/api/categories/:categoryId", middleware.RequireScopes(categoryController.Delete, "categories:write"))
+	metered.GET("/api/categories/:categoryId/products", middleware.RequireScopes(productController.FindByCategoryId, "categories:read", "products:read"))
+
+	metered.GET("/api/products", middleware.RequireScopes(productController.FindAll, "products:read"))
+	metered.GET("/api/products/:productId", middleware.RequireScopes(productController.FindById, "products:read"))
+	metered.POST("/api/products", middleware.RequireScopes(productController.Create, "products:write"))
+	metered.PUT("/api/products/:productId", middleware.RequireScopes(productController.Update, "products:write"))
+	metered.DELETE("/api/products/:productId", middleware.RequireScopes(productController.Delete, "products:write"))
+
+	// every credential may see its own usage, also once its quota is used up
+	protected.GET("/api/usage", usageController.FindOwn)
 
-	router.GET("/api/categories", categoryController.FindAll)
-	router.GET("/api/categories/:categoryId", categoryController.FindById)
-	router.POST("/api/categories", categoryController.Create)
-	router.PUT("/api/categories/:categoryId", categoryController.Update)
-	router.DELETE("/api/categories/:categoryId", categoryController.Delete)
+	metered.GET("/api/admin/backup", middleware.RequireScopes(backupController.Backup, "admin"))
+	metered.POST("/api/admin/restore", middleware.RequireScopes(backupController.Restore, "admin"))
+	metered.GET("/api/admin/api-keys", middleware.RequireScopes(apiKeyController.FindAll, "admin"))
+	metered.POST("/api/admin/api-keys", middleware.RequireScopes(apiKeyController.Issue, "admin"))
+	metered.POST("/api/admin/api-keys/:apiKeyId/rotate", middleware.RequireScopes(apiKeyController.Rotate, "admin"))
+	metered.POST("/api/admin/api-keys/:apiKeyId/revoke", middleware.RequireScopes(apiKeyController.Revoke, "admin"))
+	metered.GET("/api/admin/oauth-clients", middleware.RequireScopes(oauthController.FindAllClients, "admin"))
+	metered.POST("/api/admin/oauth-clients", middleware.RequireScopes(oauthController.RegisterClient, "admin"))
+	metered.POST("/api/admin/oauth-clients/:clientId/revoke", middleware.RequireScopes(oauthController.RevokeClient, "admin"))
 
 	router.PanicHandler = exeption.ErrorHandler
+	router.NotFound = http.HandlerFunc(exeption.NotFoundHandler)
+	router.MethodNotAllowed = http.HandlerFunc(exeption.MethodNotAllowedHandler)
 
 	return router
 }
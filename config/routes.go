@@ -0,0 +1,53 @@
+package config
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+)
+
+// RouteChains are the middleware stacks routes are registered behind.
+type RouteChains struct {
+	// Protected runs in front of every route that is not public, so it must authenticate.
+	Protected middleware.Chain
+	// Public runs in front of the routes anyone may call, like the health check and the token endpoint.
+	Public middleware.Chain
+	// Metered runs after Protected in front of the routes counted against usage quotas.
+	Metered middleware.Chain
+}
+
+// Routes registers handles on Router behind Chain.
+type Routes struct {
+	Router *httprouter.Router
+	Chain  middleware.Chain
+}
+
+// With returns Routes whose handles also run behind middlewares, after the ones of Chain.
+func (routes Routes) With(middlewares ...middleware.Middleware) Routes {
+	return Routes{Router: routes.Router, Chain: routes.Chain.Append(middlewares...)}
+}
+
+func (routes Routes) Handle(method string, path string, handle httprouter.Handle) {
+	// httprouter stores the params of a http.Handler in the request context
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		handle(writer, request, httprouter.ParamsFromContext(request.Context()))
+	})
+	routes.Router.Handler(method, path, middleware.RoutePattern(path)(routes.Chain.Then(handler)))
+}
+
+func (routes Routes) GET(path string, handle httprouter.Handle) {
+	routes.Handle(http.MethodGet, path, handle)
+}
+
+func (routes Routes) POST(path string, handle httprouter.Handle) {
+	routes.Handle(http.MethodPost, path, handle)
+}
+
+func (routes Routes) PUT(path string, handle httprouter.Handle) {
+	routes.Handle(http.MethodPut, path, handle)
+}
+
+func (routes Routes) DELETE(path string, handle httprouter.Handle) {
+	routes.Handle(http.MethodDelete, path, handle)
+}
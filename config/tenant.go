@@ -0,0 +1,34 @@
+package config
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+)
+
+const DefaultTenant = "default"
+
+// NewApiKeyTenants returns the static X-API-Key to tenant mapping accepted next to the database keys.
+// auth.ApiKeyTenants holds comma separated key:tenant pairs; without it only database keys are accepted.
+func NewApiKeyTenants(auth AuthConfig) map[string]string {
+	tenants, err := parseApiKeyTenants(auth.ApiKeyTenants)
+	helper.PanicIfError(err)
+	return tenants
+}
+
+func parseApiKeyTenants(value string) (map[string]string, error) {
+	tenants := map[string]string{}
+	if value == "" {
+		return tenants, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		key, tenant, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || key == "" || tenant == "" {
+			return nil, errors.New("API_KEY_TENANTS entries must look like key:tenant")
+		}
+		tenants[key] = tenant
+	}
+	return tenants, nil
+}
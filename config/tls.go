@@ -0,0 +1,91 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+)
+
+type TlsSettings struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ClientCaFile enables client certificate verification against the bundle.
+	ClientCaFile string `yaml:"client_ca_file"`
+	// RequireClientCert refuses connections without a certificate, otherwise one is only verified when given.
+	RequireClientCert bool `yaml:"require_client_cert"`
+	// ClientCertIdentitiesFile is read by NewClientCertIdentities.
+	ClientCertIdentitiesFile string `yaml:"client_cert_identities_file"`
+}
+
+// Enabled is false when no certificate is configured and the server should stay on plain HTTP.
+func (settings TlsSettings) Enabled() bool {
+	return settings.CertFile != "" || settings.KeyFile != ""
+}
+
+func LoadTlsConfig(settings TlsSettings) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if settings.ClientCaFile != "" {
+		bundle, err := os.ReadFile(settings.ClientCaFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(bundle) {
+			return nil, errors.New("no certificate found in " + settings.ClientCaFile)
+		}
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if settings.RequireClientCert {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	} else if settings.RequireClientCert {
+		return nil, errors.New("requiring client certificates needs a client CA file")
+	}
+
+	return tlsConfig, nil
+}
+
+// NewClientCertIdentities reads the JSON object in ClientCertIdentitiesFile, mapping certificate
+// names to {"tenant": ..., "scopes": [...]}.
+func NewClientCertIdentities(settings TlsSettings) map[string]middleware.ClientCertIdentity {
+	identities, err := loadClientCertIdentities(settings.ClientCertIdentitiesFile)
+	helper.PanicIfError(err)
+	return identities
+}
+
+func loadClientCertIdentities(path string) (map[string]middleware.ClientCertIdentity, error) {
+	identities := map[string]middleware.ClientCertIdentity{}
+	if path == "" {
+		return identities, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &identities); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for name, identity := range identities {
+		if identity.TenantId == "" {
+			return nil, errors.New("client certificate identity " + name + " has no tenant")
+		}
+	}
+	return identities, nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+type usageQuotaFile struct {
+	Default service.UsageQuota            `json:"default"`
+	Keys    map[string]service.UsageQuota `json:"keys"`
+}
+
+// NewUsageQuotas reads UsageQuotasFile, a JSON object with the "default" daily and monthly quota and
+// per credential overrides under "keys", keyed like "api_key:12". Without it usage is counted but not limited.
+func NewUsageQuotas(limits LimitsConfig) (defaults service.UsageQuota, keys map[string]service.UsageQuota) {
+	file, err := loadUsageQuotas(limits.UsageQuotasFile)
+	helper.PanicIfError(err)
+	return file.Default, file.Keys
+}
+
+func loadUsageQuotas(path string) (usageQuotaFile, error) {
+	file := usageQuotaFile{Keys: map[string]service.UsageQuota{}}
+	if path == "" {
+		return file, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return usageQuotaFile{}, err
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return usageQuotaFile{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return file, nil
+}
@@ -0,0 +1,14 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type ApiKeyController interface {
+	Issue(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Rotate(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Revoke(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
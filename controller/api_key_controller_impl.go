@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+type ApiKeyControllerImpl struct {
+	ApiKeyService service.ApiKeyService
+}
+
+func NewApiKeyController(apiKeyService service.ApiKeyService) ApiKeyController {
+	return &ApiKeyControllerImpl{
+		ApiKeyService: apiKeyService,
+	}
+}
+
+func (controller *ApiKeyControllerImpl) Issue(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	data := web.ApiKeyCreateRequest{}
+	readRequestBody(writer, request, &data)
+
+	response := controller.ApiKeyService.Issue(request.Context(), data)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   response,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ApiKeyControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	apiKeyResponses := controller.ApiKeyService.FindAll(request.Context())
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   apiKeyResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ApiKeyControllerImpl) Rotate(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := pathId(params, "apiKeyId")
+
+	response := controller.ApiKeyService.Rotate(request.Context(), id)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   response,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ApiKeyControllerImpl) Revoke(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := pathId(params, "apiKeyId")
+
+	controller.ApiKeyService.Revoke(request.Context(), id)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,12 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type BackupController interface {
+	Backup(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Restore(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
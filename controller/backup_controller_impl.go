@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+type BackupControllerImpl struct {
+	BackupService service.BackupService
+}
+
+func NewBackupController(backupService service.BackupService) BackupController {
+	return &BackupControllerImpl{
+		BackupService: backupService,
+	}
+}
+
+func (controller *BackupControllerImpl) Backup(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	format := request.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "gzip" {
+		panic(exeption.NewError(exeption.QueryParamInvalid, "format must be json or gzip"))
+	}
+
+	archive := controller.BackupService.Backup(request.Context())
+	filename := fmt.Sprintf("categories-%s.json", archive.CreatedAt.Format("20060102T150405Z"))
+
+	compress := format == "gzip"
+	if compress {
+		filename += ".gz"
+		writer.Header().Set("Content-Type", "application/gzip")
+	} else {
+		writer.Header().Set("Content-Type", "application/json")
+	}
+	writer.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	helper.WriteArchive(writer, archive, compress)
+}
+
+func (controller *BackupControllerImpl) Restore(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	query := request.URL.Query()
+	restoreRequest := web.RestoreRequest{
+		PreserveIds: parseBoolQuery(query.Get("preserve_ids"), "preserve_ids"),
+		Replace:     parseBoolQuery(query.Get("replace"), "replace"),
+	}
+
+	limitUpload(writer, request)
+	archive := web.BackupArchive{}
+	err := helper.ReadArchive(request.Body, &archive)
+	if err != nil {
+		panicIfTooLarge(err)
+		panic(exeption.NewError(exeption.BackupInvalid, "invalid backup archive: "+err.Error()))
+	}
+
+	restoreResponse := controller.BackupService.Restore(request.Context(), archive, restoreRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   restoreResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func parseBoolQuery(value string, name string) bool {
+	if value == "" {
+		return false
+	}
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		panic(exeption.NewError(exeption.QueryParamInvalid, name+" must be a boolean"))
+	}
+	return result
+}
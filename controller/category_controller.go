@@ -7,9 +7,7 @@ import (
 )
 
 type CategoryController interface {
-	Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
-	Update(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
-	Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
-	FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
-	FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	CrudController
+	Export(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Import(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
 }
@@ -1,93 +1,106 @@
 package controller
 
 import (
+	"encoding/csv"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/exeption"
 	"github.com/mrakhaf/golang-restful-api/helper"
 	"github.com/mrakhaf/golang-restful-api/model/web"
 	"github.com/mrakhaf/golang-restful-api/service"
 )
 
+// CategoryControllerImpl takes the CRUD handlers from CrudControllerImpl and adds cascading delete, import and export.
 type CategoryControllerImpl struct {
+	*CrudControllerImpl[web.CategoryCreateRequest, web.CategoryUpdateRequest, web.CategoryResponse]
 	CategoryService service.CategoryService
 }
 
 func NewCategoryController(categoryService service.CategoryService) CategoryController {
 	return &CategoryControllerImpl{
+		CrudControllerImpl: &CrudControllerImpl[web.CategoryCreateRequest, web.CategoryUpdateRequest, web.CategoryResponse]{
+			Service: categoryService,
+			IdParam: "categoryId",
+			SetUpdateRequestId: func(request *web.CategoryUpdateRequest, id int) {
+				request.Id = id
+			},
+		},
 		CategoryService: categoryService,
 	}
 }
 
-func (controller *CategoryControllerImpl) Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	data := web.CategoryCreateRequest{}
-	helper.ReadFromRequestBody(request, &data)
+func (controller *CategoryControllerImpl) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if !parseBoolQuery(request.URL.Query().Get("cascade"), "cascade") {
+		controller.CrudControllerImpl.Delete(writer, request, params)
+		return
+	}
 
-	response := controller.CategoryService.Create(request.Context(), data)
+	controller.CategoryService.DeleteCascade(request.Context(), controller.id(params))
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
-		Data:   response,
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
 }
 
-func (controller *CategoryControllerImpl) Update(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	data := web.CategoryUpdateRequest{}
-	helper.ReadFromRequestBody(request, &data)
-
-	categoryId := params.ByName("categoryId")
-	id, err := strconv.Atoi(categoryId)
-	helper.PanicIfError(err)
-	data.Id = id
-
-	response := controller.CategoryService.Update(request.Context(), data)
-	webResponse := web.WebResponse{
-		Code:   200,
-		Status: "OK",
-		Data:   response,
+func (controller *CategoryControllerImpl) Export(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	format := request.URL.Query().Get("format")
+	if format != "" && format != "csv" {
+		panic(exeption.NewError(exeption.QueryParamInvalid, "unsupported export format: "+format))
 	}
 
-	helper.WriteToResponseBody(writer, webResponse)
-}
+	writer.Header().Set("Content-Type", "text/csv")
+	writer.Header().Set("Content-Disposition", `attachment; filename="categories.csv"`)
 
-func (controller *CategoryControllerImpl) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	categoryId := params.ByName("categoryId")
-	id, err := strconv.Atoi(categoryId)
+	// rows are written while they are read, so the export never holds every category in memory
+	csvWriter := csv.NewWriter(writer)
+	err := csvWriter.Write([]string{"id", "name"})
 	helper.PanicIfError(err)
-
-	controller.CategoryService.Delete(request.Context(), id)
-	webResponse := web.WebResponse{
-		Code:   200,
-		Status: "OK",
-	}
-
-	helper.WriteToResponseBody(writer, webResponse)
+	controller.CategoryService.Export(request.Context(), func(categoryResponse web.CategoryResponse) {
+		err := csvWriter.Write([]string{strconv.Itoa(categoryResponse.Id), helper.EscapeCsvCell(categoryResponse.Name)})
+		helper.PanicIfError(err)
+	})
+	csvWriter.Flush()
+	helper.PanicIfError(csvWriter.Error())
 }
 
-func (controller *CategoryControllerImpl) FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	categoryId := params.ByName("categoryId")
-	id, err := strconv.Atoi(categoryId)
-	helper.PanicIfError(err)
+func (controller *CategoryControllerImpl) Import(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	query := request.URL.Query()
+	importRequest := web.CategoryImportRequest{
+		DryRun:   parseBoolQuery(query.Get("dry_run"), "dry_run"),
+		Language: request.Header.Get("Accept-Language"),
+	}
 
-	categoryResponse := controller.CategoryService.FindById(request.Context(), id)
-	webResponse := web.WebResponse{
-		Code:   200,
-		Status: "OK",
-		Data:   categoryResponse,
+	switch query.Get("mode") {
+	case "", "create":
+	case "upsert":
+		importRequest.Upsert = true
+	default:
+		panic(exeption.NewError(exeption.QueryParamInvalid, "mode must be create or upsert"))
 	}
 
-	helper.WriteToResponseBody(writer, webResponse)
-}
+	limitUpload(writer, request)
+	var body io.Reader = request.Body
+	if strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := request.FormFile("file")
+		if err != nil {
+			panicIfTooLarge(err)
+			panic(exeption.NewError(exeption.CategoryCsvInvalid, "multipart upload must contain a file field"))
+		}
+		defer file.Close()
+		body = file
+	}
 
-func (controller *CategoryControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	categoryResponses := controller.CategoryService.FindAll(request.Context())
+	importResponse := controller.CategoryService.Import(request.Context(), body, importRequest)
 	webResponse := web.WebResponse{
 		Code:   200,
 		Status: "OK",
-		Data:   categoryResponses,
+		Data:   importResponse,
 	}
 
 	helper.WriteToResponseBody(writer, webResponse)
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+// CrudControllerImpl serves a CrudService over the routes registered for one resource.
+// IdParam names the route parameter holding the id, SetUpdateRequestId copies it into the update request.
+type CrudControllerImpl[C any, U any, R any] struct {
+	Service            service.CrudService[C, U, R]
+	IdParam            string
+	SetUpdateRequestId func(request *U, id int)
+}
+
+func NewCrudController[C any, U any, R any](crudService service.CrudService[C, U, R], idParam string, setUpdateRequestId func(request *U, id int)) CrudController {
+	return &CrudControllerImpl[C, U, R]{
+		Service:            crudService,
+		IdParam:            idParam,
+		SetUpdateRequestId: setUpdateRequestId,
+	}
+}
+
+func (controller *CrudControllerImpl[C, U, R]) Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	var data C
+	readRequestBody(writer, request, &data)
+
+	response := controller.Service.Create(request.Context(), data)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   response,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CrudControllerImpl[C, U, R]) Update(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	var data U
+	readRequestBody(writer, request, &data)
+	controller.SetUpdateRequestId(&data, controller.id(params))
+
+	response := controller.Service.Update(request.Context(), data)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   response,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CrudControllerImpl[C, U, R]) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	controller.Service.Delete(request.Context(), controller.id(params))
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CrudControllerImpl[C, U, R]) FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	response := controller.Service.FindById(request.Context(), controller.id(params))
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   response,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CrudControllerImpl[C, U, R]) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	responses := controller.Service.FindAll(request.Context())
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   responses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CrudControllerImpl[C, U, R]) id(params httprouter.Params) int {
+	return pathId(params, controller.IdParam)
+}
@@ -0,0 +1,11 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type HealthController interface {
+	Check(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
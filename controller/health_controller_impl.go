@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+type HealthControllerImpl struct {
+	DB *sql.DB
+}
+
+func NewHealthController(db *sql.DB) HealthController {
+	return &HealthControllerImpl{
+		DB: db,
+	}
+}
+
+// Check answers 503 when the database cannot be reached.
+func (controller *HealthControllerImpl) Check(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	webResponse := web.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   "UP",
+	}
+
+	if err := controller.DB.PingContext(request.Context()); err != nil {
+		webResponse = web.WebResponse{
+			Code:   http.StatusServiceUnavailable,
+			Status: "SERVICE UNAVAILABLE",
+			Data:   "DOWN",
+		}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(webResponse.Code)
+	helper.WriteToResponseBody(writer, webResponse)
+}
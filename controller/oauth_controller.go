@@ -0,0 +1,16 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type OauthController interface {
+	Token(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Introspect(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	Revoke(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	RegisterClient(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	FindAllClients(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+	RevokeClient(writer http.ResponseWriter, request *http.Request, params httprouter.Params)
+}
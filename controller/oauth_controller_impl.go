@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+type OauthControllerImpl struct {
+	OauthService service.OauthService
+}
+
+func NewOauthController(oauthService service.OauthService) OauthController {
+	return &OauthControllerImpl{
+		OauthService: oauthService,
+	}
+}
+
+func (controller *OauthControllerImpl) Token(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	credentials := readOauthForm(request)
+	tokenRequest := web.OauthTokenRequest{
+		OauthClientCredentials: credentials,
+		GrantType:              request.PostForm.Get("grant_type"),
+		Scope:                  request.PostForm.Get("scope"),
+	}
+
+	response := controller.OauthService.Token(request.Context(), tokenRequest)
+
+	writer.Header().Set("Cache-Control", "no-store")
+	helper.WriteToResponseBody(writer, response)
+}
+
+func (controller *OauthControllerImpl) Introspect(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	credentials := readOauthForm(request)
+	lookupRequest := web.OauthTokenLookupRequest{OauthClientCredentials: credentials, Token: request.PostForm.Get("token")}
+
+	response := controller.OauthService.Introspect(request.Context(), lookupRequest)
+
+	writer.Header().Set("Cache-Control", "no-store")
+	helper.WriteToResponseBody(writer, response)
+}
+
+func (controller *OauthControllerImpl) Revoke(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	credentials := readOauthForm(request)
+	lookupRequest := web.OauthTokenLookupRequest{OauthClientCredentials: credentials, Token: request.PostForm.Get("token")}
+
+	controller.OauthService.Revoke(request.Context(), lookupRequest)
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+func (controller *OauthControllerImpl) RegisterClient(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	data := web.OauthClientCreateRequest{}
+	readRequestBody(writer, request, &data)
+
+	response := controller.OauthService.RegisterClient(request.Context(), data)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   response,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *OauthControllerImpl) FindAllClients(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	clientResponses := controller.OauthService.FindAllClients(request.Context())
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   clientResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *OauthControllerImpl) RevokeClient(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := pathId(params, "clientId")
+
+	controller.OauthService.RevokeClient(request.Context(), id)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+// readOauthForm parses the urlencoded body and takes the client credentials from HTTP Basic
+// authentication, falling back to the client_id and client_secret fields (RFC 6749 section 2.3.1).
+func readOauthForm(request *http.Request) web.OauthClientCredentials {
+	err := request.ParseForm()
+	if err != nil {
+		panic(exeption.NewOauthError(exeption.OauthInvalidRequest, err.Error()))
+	}
+
+	if clientId, clientSecret, ok := request.BasicAuth(); ok {
+		return web.OauthClientCredentials{ClientId: clientId, ClientSecret: clientSecret}
+	}
+	return web.OauthClientCredentials{
+		ClientId:     request.PostForm.Get("client_id"),
+		ClientSecret: request.PostForm.Get("client_secret"),
+	}
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+type ProductControllerImpl struct {
+	ProductService service.ProductService
+}
+
+func NewProductController(productService service.ProductService) ProductController {
+	return &ProductControllerImpl{
+		ProductService: productService,
+	}
+}
+
+func (controller *ProductControllerImpl) Create(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	data := web.ProductCreateRequest{}
+	readRequestBody(writer, request, &data)
+
+	response := controller.ProductService.Create(request.Context(), data)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   response,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ProductControllerImpl) Update(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	data := web.ProductUpdateRequest{}
+	readRequestBody(writer, request, &data)
+
+	id := pathId(params, "productId")
+	data.Id = id
+
+	response := controller.ProductService.Update(request.Context(), data)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   response,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ProductControllerImpl) Delete(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := pathId(params, "productId")
+
+	controller.ProductService.Delete(request.Context(), id)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ProductControllerImpl) FindById(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := pathId(params, "productId")
+
+	productResponse := controller.ProductService.FindById(request.Context(), id)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   productResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ProductControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	productResponses := controller.ProductService.FindAll(request.Context())
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   productResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ProductControllerImpl) FindByCategoryId(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := pathId(params, "categoryId")
+
+	productResponses := controller.ProductService.FindByCategoryId(request.Context(), id)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   productResponses,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
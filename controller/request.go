@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+)
+
+// MaxRequestBodyBytes caps JSON request bodies, the decoding stops once a body grows past it.
+var MaxRequestBodyBytes int64 = 1 << 20
+
+// MaxUploadBytes caps the csv imports and backup archives, which are read as streams instead of decoded.
+var MaxUploadBytes int64 = 32 << 20
+
+// limitUpload caps the body at MaxUploadBytes. Reading past it fails with an *http.MaxBytesError,
+// which exeption.ErrorHandler answers as REQUEST_BODY_INVALID wherever the read happens.
+func limitUpload(writer http.ResponseWriter, request *http.Request) {
+	request.Body = http.MaxBytesReader(writer, request.Body, MaxUploadBytes)
+}
+
+// panicIfTooLarge raises the error of a body over its limit as it is, so it is not reported as invalid content.
+func panicIfTooLarge(err error) {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		panic(err)
+	}
+}
+
+// readRequestBody decodes the JSON body into result. A body that is not application/json is refused
+// with 415; malformed JSON, unknown fields, trailing data and a body over MaxRequestBodyBytes with 400.
+func readRequestBody(writer http.ResponseWriter, request *http.Request, result interface{}) {
+	mediaType, mediaParams, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		panic(exeption.NewError(exeption.ContentTypeUnsupported, ""))
+	}
+	if charset, ok := mediaParams["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		panic(exeption.NewError(exeption.ContentTypeUnsupported, "request body must be encoded in utf-8"))
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(writer, request.Body, MaxRequestBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(result)
+	if err != nil {
+		panic(exeption.NewError(exeption.RequestBodyInvalid, decodeErrorMessage(err)))
+	}
+	// a second value, even a valid one, means the client sent something else than it meant to
+	err = decoder.Decode(&struct{}{})
+	if !errors.Is(err, io.EOF) {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			panic(exeption.NewError(exeption.RequestBodyInvalid, decodeErrorMessage(err)))
+		}
+		panic(exeption.NewError(exeption.RequestBodyInvalid, "request body must contain a single JSON value"))
+	}
+}
+
+func decodeErrorMessage(err error) string {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	var maxBytesError *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &syntaxError):
+		return fmt.Sprintf("request body contains malformed JSON at offset %d", syntaxError.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "request body contains malformed JSON"
+	case errors.As(err, &typeError):
+		if typeError.Field != "" {
+			return fmt.Sprintf("field %s must be a JSON %s", typeError.Field, jsonKind(typeError.Type))
+		}
+		return fmt.Sprintf("request body must be a JSON %s", jsonKind(typeError.Type))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// the decoder has no error type for unknown fields
+		return "request body contains unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	case errors.Is(err, io.EOF):
+		return "request body must not be empty"
+	case errors.As(err, &maxBytesError):
+		return fmt.Sprintf("request body must not be larger than %d bytes", maxBytesError.Limit)
+	default:
+		return exeption.RequestBodyInvalid.Message
+	}
+}
+
+// jsonKind names the JSON value expected for a Go type, so clients never see Go type names.
+func jsonKind(goType reflect.Type) string {
+	for goType.Kind() == reflect.Pointer {
+		goType = goType.Elem()
+	}
+	if goType.Implements(textUnmarshalerType) || reflect.PointerTo(goType).Implements(textUnmarshalerType) {
+		return "string"
+	}
+	switch goType.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "value"
+	}
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// pathId reads the positive integer id in the route parameter name.
+func pathId(params httprouter.Params, name string) int {
+	id, err := strconv.Atoi(params.ByName(name))
+	if err != nil || id <= 0 {
+		panic(exeption.NewError(exeption.PathParamInvalid, name+" must be a positive integer"))
+	}
+	return id
+}
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+type UsageControllerImpl struct {
+	UsageService service.UsageService
+}
+
+func NewUsageController(usageService service.UsageService) UsageController {
+	return &UsageControllerImpl{
+		UsageService: usageService,
+	}
+}
+
+func (controller *UsageControllerImpl) FindOwn(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	usageResponse := controller.UsageService.FindOwn(request.Context())
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   usageResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
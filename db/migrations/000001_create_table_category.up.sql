@@ -0,0 +1,6 @@
+CREATE TABLE category
+(
+    id   INT          NOT NULL AUTO_INCREMENT,
+    name VARCHAR(200) NOT NULL,
+    PRIMARY KEY (id)
+) ENGINE = InnoDB;
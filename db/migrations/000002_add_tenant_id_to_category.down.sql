@@ -0,0 +1,3 @@
+ALTER TABLE category
+    DROP INDEX category_tenant_id_idx,
+    DROP COLUMN tenant_id;
@@ -0,0 +1,3 @@
+ALTER TABLE category
+    ADD COLUMN tenant_id VARCHAR(100) NOT NULL DEFAULT 'default' AFTER id,
+    ADD INDEX category_tenant_id_idx (tenant_id);
@@ -0,0 +1,11 @@
+CREATE TABLE product
+(
+    id          INT          NOT NULL AUTO_INCREMENT,
+    tenant_id   VARCHAR(100) NOT NULL,
+    category_id INT          NOT NULL,
+    name        VARCHAR(200) NOT NULL,
+    price       BIGINT       NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    INDEX product_tenant_id_idx (tenant_id),
+    CONSTRAINT product_category_id_fk FOREIGN KEY (category_id) REFERENCES category (id)
+) ENGINE = InnoDB;
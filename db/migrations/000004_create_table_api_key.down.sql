@@ -0,0 +1 @@
+DROP TABLE api_key;
@@ -0,0 +1,16 @@
+CREATE TABLE api_key
+(
+    id           INT          NOT NULL AUTO_INCREMENT,
+    tenant_id    VARCHAR(100) NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    owner        VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(16)  NOT NULL,
+    key_hash     CHAR(64)     NOT NULL,
+    created_at   DATETIME     NOT NULL,
+    expires_at   DATETIME     NULL,
+    last_used_at DATETIME     NULL,
+    revoked      BOOLEAN      NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id),
+    UNIQUE INDEX api_key_key_hash_idx (key_hash),
+    INDEX api_key_tenant_id_idx (tenant_id)
+) ENGINE = InnoDB;
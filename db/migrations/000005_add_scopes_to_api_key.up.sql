@@ -0,0 +1,5 @@
+-- keys issued before scopes existed keep full access until they are rotated or revoked
+ALTER TABLE api_key
+    ADD COLUMN scopes VARCHAR(500) NOT NULL DEFAULT '*' AFTER key_hash;
+ALTER TABLE api_key
+    ALTER COLUMN scopes DROP DEFAULT;
@@ -0,0 +1,14 @@
+CREATE TABLE oauth_client
+(
+    id          INT          NOT NULL AUTO_INCREMENT,
+    tenant_id   VARCHAR(100) NOT NULL,
+    client_id   VARCHAR(32)  NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    secret_hash CHAR(64)     NOT NULL,
+    scopes      VARCHAR(500) NOT NULL,
+    created_at  DATETIME     NOT NULL,
+    revoked     BOOLEAN      NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id),
+    UNIQUE INDEX oauth_client_client_id_idx (client_id),
+    INDEX oauth_client_tenant_id_idx (tenant_id)
+) ENGINE = InnoDB;
@@ -0,0 +1,8 @@
+CREATE TABLE oauth_revoked_token
+(
+    jti        CHAR(32)    NOT NULL,
+    client_id  VARCHAR(32) NOT NULL,
+    expires_at DATETIME    NOT NULL,
+    PRIMARY KEY (jti),
+    INDEX oauth_revoked_token_expires_at_idx (expires_at)
+) ENGINE = InnoDB;
@@ -0,0 +1,9 @@
+CREATE TABLE usage_counter
+(
+    tenant_id     VARCHAR(100) NOT NULL,
+    credential    VARCHAR(150) NOT NULL,
+    route_class   VARCHAR(10)  NOT NULL,
+    day           DATE         NOT NULL,
+    request_count BIGINT       NOT NULL,
+    PRIMARY KEY (tenant_id, credential, day, route_class)
+) ENGINE = InnoDB;
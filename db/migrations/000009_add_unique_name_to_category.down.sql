@@ -0,0 +1,2 @@
+ALTER TABLE category
+    DROP INDEX category_tenant_id_name_idx;
@@ -0,0 +1,12 @@
+-- names were not unique before, so every duplicate but the oldest is renamed to "name (id)" first;
+-- products keep pointing at the renamed categories
+UPDATE category duplicate
+    JOIN (SELECT tenant_id, name, MIN(id) AS kept_id
+          FROM category
+          GROUP BY tenant_id, name
+          HAVING COUNT(*) > 1) kept
+    ON duplicate.tenant_id = kept.tenant_id AND duplicate.name = kept.name AND duplicate.id <> kept.kept_id
+SET duplicate.name = CONCAT(LEFT(duplicate.name, 200 - LENGTH(duplicate.id) - 3), ' (', duplicate.id, ')');
+
+ALTER TABLE category
+    ADD UNIQUE INDEX category_tenant_id_name_idx (tenant_id, name);
@@ -0,0 +1,91 @@
+package exeption
+
+//go:generate go run ../cmd/errorcodes -spec ../apispec.json -docs ../docs/error-codes.md
+
+import "net/http"
+
+// ErrorCode is a stable, machine readable error clients can branch on. It is sent as "error_code"
+// in every error response, next to its HTTP status.
+type ErrorCode struct {
+	Code    string
+	Status  int
+	Message string
+}
+
+// Error is an error of the catalog. Detail replaces the default message of the code when set.
+type Error struct {
+	ErrorCode
+	Detail string
+}
+
+func NewError(code ErrorCode, detail string) Error {
+	return Error{ErrorCode: code, Detail: detail}
+}
+
+func (err Error) Message() string {
+	if err.Detail != "" {
+		return err.Detail
+	}
+	return err.ErrorCode.Message
+}
+
+// The generic codes answer the errors that have no more specific code, like a NotFoundError.
+var (
+	BadRequest           = ErrorCode{"BAD_REQUEST", http.StatusBadRequest, "request is invalid"}
+	Unauthorized         = ErrorCode{"UNAUTHORIZED", http.StatusUnauthorized, "credentials are missing or invalid"}
+	Forbidden            = ErrorCode{"FORBIDDEN", http.StatusForbidden, "request is not allowed"}
+	NotFound             = ErrorCode{"NOT_FOUND", http.StatusNotFound, "resource is not found"}
+	MethodNotAllowed     = ErrorCode{"METHOD_NOT_ALLOWED", http.StatusMethodNotAllowed, "method is not allowed on the resource"}
+	Conflict             = ErrorCode{"CONFLICT", http.StatusConflict, "request conflicts with the current state"}
+	UnsupportedMediaType = ErrorCode{"UNSUPPORTED_MEDIA_TYPE", http.StatusUnsupportedMediaType, "content type is not supported"}
+	RateLimited          = ErrorCode{"RATE_LIMITED", http.StatusTooManyRequests, "too many requests, retry later"}
+	InternalError        = ErrorCode{"INTERNAL_ERROR", http.StatusInternalServerError, "internal server error"}
+)
+
+var (
+	ValidationFailed       = ErrorCode{"VALIDATION_FAILED", http.StatusBadRequest, "validation failed"}
+	RequestBodyInvalid     = ErrorCode{"REQUEST_BODY_INVALID", http.StatusBadRequest, "request body is invalid"}
+	ContentTypeUnsupported = ErrorCode{"CONTENT_TYPE_UNSUPPORTED", http.StatusUnsupportedMediaType, "Content-Type must be application/json"}
+	PathParamInvalid       = ErrorCode{"PATH_PARAM_INVALID", http.StatusBadRequest, "path parameter is invalid"}
+	QueryParamInvalid      = ErrorCode{"QUERY_PARAM_INVALID", http.StatusBadRequest, "query parameter is invalid"}
+	ScopeMissing           = ErrorCode{"SCOPE_MISSING", http.StatusForbidden, "credential lacks a required scope"}
+	QuotaExceeded          = ErrorCode{"QUOTA_EXCEEDED", http.StatusTooManyRequests, "usage quota exceeded"}
+
+	CategoryNotFound         = ErrorCode{"CATEGORY_NOT_FOUND", http.StatusNotFound, "category is not found"}
+	CategoryHasProducts      = ErrorCode{"CATEGORY_HAS_PRODUCTS", http.StatusConflict, "category still has products"}
+	CategoryNameTaken        = ErrorCode{"CATEGORY_NAME_TAKEN", http.StatusConflict, "category name is already taken"}
+	CategoryIdTaken          = ErrorCode{"CATEGORY_ID_TAKEN", http.StatusConflict, "category id is already used by another tenant"}
+	CategoryCsvInvalid       = ErrorCode{"CATEGORY_CSV_INVALID", http.StatusBadRequest, "category csv is invalid"}
+	ProductNotFound          = ErrorCode{"PRODUCT_NOT_FOUND", http.StatusNotFound, "product is not found"}
+	ProductCategoryInvalid   = ErrorCode{"PRODUCT_CATEGORY_INVALID", http.StatusBadRequest, "category of the product is not found"}
+	BackupInvalid            = ErrorCode{"BACKUP_INVALID", http.StatusBadRequest, "backup archive is invalid"}
+	BackupVersionUnsupported = ErrorCode{"BACKUP_VERSION_UNSUPPORTED", http.StatusBadRequest, "backup schema version is not supported"}
+	BackupReplaceBlocked     = ErrorCode{"BACKUP_REPLACE_BLOCKED", http.StatusConflict, "cannot replace categories that products reference"}
+	ApiKeyNotFound           = ErrorCode{"API_KEY_NOT_FOUND", http.StatusNotFound, "api key is not found"}
+	ApiKeyRevoked            = ErrorCode{"API_KEY_REVOKED", http.StatusConflict, "api key is already revoked"}
+	ApiKeyExpired            = ErrorCode{"API_KEY_EXPIRED", http.StatusConflict, "api key is expired"}
+	ApiKeyExpiryInPast       = ErrorCode{"API_KEY_EXPIRY_IN_PAST", http.StatusBadRequest, "expires_at must be in the future"}
+	OauthClientNotFound      = ErrorCode{"OAUTH_CLIENT_NOT_FOUND", http.StatusNotFound, "oauth client is not found"}
+)
+
+// The oauth codes are answered in the RFC 6749 format, their "error" being the code without the
+// OAUTH_ prefix in lower case, see NewOauthError.
+var (
+	OauthInvalidRequest       = ErrorCode{"OAUTH_INVALID_REQUEST", http.StatusBadRequest, "token request is invalid"}
+	OauthInvalidClient        = ErrorCode{"OAUTH_INVALID_CLIENT", http.StatusUnauthorized, "client authentication failed"}
+	OauthUnauthorizedClient   = ErrorCode{"OAUTH_UNAUTHORIZED_CLIENT", http.StatusBadRequest, "client is not allowed to do this"}
+	OauthUnsupportedGrantType = ErrorCode{"OAUTH_UNSUPPORTED_GRANT_TYPE", http.StatusBadRequest, "grant type is not supported"}
+	OauthInvalidScope         = ErrorCode{"OAUTH_INVALID_SCOPE", http.StatusBadRequest, "requested scope is not granted"}
+)
+
+// ErrorCodes is the catalog, in the order it is documented.
+var ErrorCodes = []ErrorCode{
+	BadRequest, Unauthorized, Forbidden, NotFound, MethodNotAllowed, Conflict, UnsupportedMediaType, RateLimited, InternalError,
+	ValidationFailed, RequestBodyInvalid, ContentTypeUnsupported, PathParamInvalid, QueryParamInvalid, ScopeMissing, QuotaExceeded,
+	CategoryNotFound, CategoryHasProducts, CategoryNameTaken, CategoryIdTaken, CategoryCsvInvalid,
+	ProductNotFound, ProductCategoryInvalid,
+	BackupInvalid, BackupVersionUnsupported, BackupReplaceBlocked,
+	ApiKeyNotFound, ApiKeyRevoked, ApiKeyExpired, ApiKeyExpiryInPast,
+	OauthClientNotFound,
+	OauthInvalidRequest, OauthInvalidClient, OauthUnauthorizedClient, OauthUnsupportedGrantType, OauthInvalidScope,
+}
@@ -1,7 +1,14 @@
 package exeption
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"mime"
 	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/mrakhaf/golang-restful-api/helper"
@@ -9,29 +16,42 @@ import (
 )
 
 func ErrorHandler(writer http.ResponseWriter, request *http.Request, err interface{}) {
+	if catalogError(writer, request, err) {
+		return
+	}
 	if notFoundError(writer, request, err) {
 		return
 	}
 	if validationErrors(writer, request, err) {
 		return
 	}
+	if oauthError(writer, request, err) {
+		return
+	}
+	if bodyTooLargeError(writer, request, err) {
+		return
+	}
 	internalServerError(writer, request, err)
 
 }
 
+func catalogError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
+	exeption, ok := err.(Error)
+	if ok {
+		WriteError(writer, request, exeption.ErrorCode, exeption.Message())
+		return true
+	} else {
+		return false
+	}
+}
+
 func validationErrors(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
 	exeption, ok := err.(validator.ValidationErrors)
 	if ok {
-		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusBadRequest)
-
-		webResponse := web.WebResponse{
-			Code:   http.StatusBadRequest,
-			Status: "BAD REQUEST",
-			Data:   exeption.Error(),
-		}
+		validationErrors := helper.ToValidationErrors(exeption, helper.Translator(request.Header.Get("Accept-Language")))
 
-		helper.WriteToResponseBody(writer, webResponse)
+		problem := web.Problem{Detail: ValidationFailed.Message, Extensions: map[string]interface{}{"errors": validationErrors}}
+		writeError(writer, request, ValidationFailed, validationErrors, problem)
 		return true
 	} else {
 		return false
@@ -41,31 +61,157 @@ func validationErrors(writer http.ResponseWriter, request *http.Request, err int
 func notFoundError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
 	exeption, ok := err.(NotFoundError)
 	if ok {
-		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusNotFound)
+		WriteError(writer, request, NotFound, exeption.Error)
+		return true
+	} else {
+		return false
+	}
+}
 
-		webResponse := web.WebResponse{
-			Code:   http.StatusNotFound,
-			Status: "NOT FOUND",
-			Data:   exeption.Error,
+func oauthError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
+	exeption, ok := err.(OauthError)
+	if ok {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("Cache-Control", "no-store")
+		if exeption.Status == http.StatusUnauthorized {
+			writer.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
 		}
+		writer.WriteHeader(exeption.Status)
 
-		helper.WriteToResponseBody(writer, webResponse)
+		// RFC 6749 allows extra members, error_code keeps the body in line with the other errors
+		helper.WriteToResponseBody(writer, map[string]string{
+			"error":             exeption.ErrorName(),
+			"error_description": exeption.Description,
+			"error_code":        exeption.Code,
+		})
 		return true
 	} else {
 		return false
 	}
 }
 
+// bodyTooLargeError answers a read past an http.MaxBytesReader, raised by whichever layer read the body.
+func bodyTooLargeError(writer http.ResponseWriter, request *http.Request, err interface{}) bool {
+	cause, ok := err.(error)
+	var maxBytesError *http.MaxBytesError
+	if ok && errors.As(cause, &maxBytesError) {
+		WriteError(writer, request, RequestBodyInvalid, fmt.Sprintf("request body must not be larger than %d bytes", maxBytesError.Limit))
+		return true
+	} else {
+		return false
+	}
+}
+
+// Debug adds the panic value and stack to internal server error bodies. It must stay off in production,
+// where they could leak driver errors and SQL to clients.
+var Debug = false
+
+// ProblemJson answers every error with an RFC 7807 problem+json body, otherwise only clients
+// accepting application/problem+json get one and the others the WebResponse envelope.
+var ProblemJson = false
+
 func internalServerError(writer http.ResponseWriter, request *http.Request, err interface{}) {
-	writer.Header().Set("Content-Type", "application/json")
-	writer.WriteHeader(http.StatusInternalServerError)
+	stack := string(debug.Stack())
+	// every error gets its own reference, a client sent request id could be reused or guessed; the log
+	// record carries both so the stack is found from either
+	reference := helper.NewRequestId()
+	helper.Logger(request.Context()).Error("panic", "reference", reference, "error", fmt.Sprint(err), "stack", stack)
+
+	details := map[string]interface{}{"reference": reference}
+	if Debug {
+		details["error"] = fmt.Sprint(err)
+		details["stack"] = strings.Split(stack, "\n")
+	}
+
+	data := map[string]interface{}{"message": InternalError.Message}
+	for name, value := range details {
+		data[name] = value
+	}
+	problem := web.Problem{Detail: InternalError.Message, Extensions: details}
+	writeError(writer, request, InternalError, data, problem)
+}
+
+// NotFoundHandler answers requests for unknown paths.
+func NotFoundHandler(writer http.ResponseWriter, request *http.Request) {
+	WriteError(writer, request, NotFound, NotFound.Message)
+}
+
+// MethodNotAllowedHandler answers requests with a method the path does not support,
+// the router having set the Allow header.
+func MethodNotAllowedHandler(writer http.ResponseWriter, request *http.Request) {
+	WriteError(writer, request, MethodNotAllowed, MethodNotAllowed.Message)
+}
+
+// WriteError answers with the status of code, as a WebResponse carrying data or as a problem+json body,
+// see ProblemJson. A string data becomes the detail of the problem.
+func WriteError(writer http.ResponseWriter, request *http.Request, code ErrorCode, data interface{}) {
+	problem := web.Problem{}
+	if detail, ok := data.(string); ok {
+		problem.Detail = detail
+	}
+	writeError(writer, request, code, data, problem)
+}
+
+func writeError(writer http.ResponseWriter, request *http.Request, code ErrorCode, data interface{}, problem web.Problem) {
+	status := code.Status
+	requestId := helper.RequestIdFromContext(request.Context())
 
-	webResponse := web.WebResponse{
-		Code:   http.StatusInternalServerError,
-		Status: "INTERNAL SERVER ERROR",
-		Data:   err,
+	if !ProblemJson && !acceptsProblem(request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(status)
+
+		webResponse := web.WebResponse{
+			Code:      status,
+			Status:    strings.ToUpper(http.StatusText(status)),
+			Data:      data,
+			ErrorCode: code.Code,
+			RequestId: requestId,
+		}
+
+		helper.WriteToResponseBody(writer, webResponse)
+		return
+	}
+
+	problem.Type = "about:blank"
+	problem.Title = http.StatusText(status)
+	problem.Status = status
+	problem.Instance = request.URL.Path
+	extensions := map[string]interface{}{"error_code": code.Code}
+	if requestId != "" {
+		extensions["request_id"] = requestId
+	}
+	for name, value := range problem.Extensions {
+		extensions[name] = value
 	}
+	problem.Extensions = extensions
+
+	writer.Header().Set("Content-Type", "application/problem+json")
+	writer.WriteHeader(status)
+	helper.WriteToResponseBody(writer, problem)
+}
 
-	helper.WriteToResponseBody(writer, webResponse)
+// acceptsProblem is true when Accept lists application/problem+json with a quality above 0 and
+// at least that of application/json or a wildcard, the envelope being what those get.
+func acceptsProblem(request *http.Request) bool {
+	problemQuality, jsonQuality := 0.0, 0.0
+	for _, accepted := range strings.Split(request.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(accepted)
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if value, ok := params["q"]; ok {
+			// an invalid quality refuses the media type rather than guessing one
+			if quality, err = strconv.ParseFloat(value, 64); err != nil {
+				quality = 0
+			}
+		}
+		switch mediaType {
+		case "application/problem+json":
+			problemQuality = math.Max(problemQuality, quality)
+		case "application/json", "application/*", "*/*":
+			jsonQuality = math.Max(jsonQuality, quality)
+		}
+	}
+	return problemQuality > 0 && problemQuality >= jsonQuality
 }
@@ -0,0 +1,18 @@
+package exeption
+
+import "strings"
+
+// OauthError is answered in the RFC 6749 error format instead of WebResponse.
+type OauthError struct {
+	ErrorCode
+	Description string
+}
+
+func NewOauthError(code ErrorCode, description string) OauthError {
+	return OauthError{ErrorCode: code, Description: description}
+}
+
+// ErrorName is the RFC 6749 error code, like "invalid_client".
+func (err OauthError) ErrorName() string {
+	return strings.ToLower(strings.TrimPrefix(err.Code, "OAUTH_"))
+}
@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MaxArchiveBytes caps a gzip archive once decompressed, the upload limit only bounds the compressed size.
+var MaxArchiveBytes int64 = 256 << 20
+
+func WriteArchive(writer io.Writer, archive interface{}, compress bool) {
+	if compress {
+		gzipWriter := gzip.NewWriter(writer)
+		defer func() {
+			PanicIfError(gzipWriter.Close())
+		}()
+		writer = gzipWriter
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(archive)
+	PanicIfError(err)
+}
+
+// ReadArchive accepts both plain JSON and gzip archives, telling them apart by the gzip magic number.
+func ReadArchive(reader io.Reader, archive interface{}) error {
+	bufferedReader := bufio.NewReader(reader)
+	magic, _ := bufferedReader.Peek(2)
+
+	var source io.Reader = bufferedReader
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(bufferedReader)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		source = &cappedReader{reader: io.LimitReader(gzipReader, MaxArchiveBytes+1), left: MaxArchiveBytes}
+	}
+
+	return json.NewDecoder(source).Decode(archive)
+}
+
+// cappedReader fails once more than left bytes were read, instead of ending quietly like io.LimitReader.
+type cappedReader struct {
+	reader io.Reader
+	left   int64
+}
+
+func (reader *cappedReader) Read(buffer []byte) (int, error) {
+	n, err := reader.reader.Read(buffer)
+	reader.left -= int64(n)
+	if reader.left < 0 {
+		return n, fmt.Errorf("archive is larger than %d bytes once decompressed", MaxArchiveBytes)
+	}
+	return n, err
+}
@@ -0,0 +1,27 @@
+package helper
+
+import "strings"
+
+// formulaStarts are the first characters that make spreadsheets evaluate a cell as a formula.
+const formulaStarts = "=+-@\t\r"
+
+// EscapeCsvCell prefixes a cell a spreadsheet would evaluate as a formula with ', which it then shows as text.
+// A value already looking escaped, like '=x, gets one more ' so it survives UnescapeCsvCell.
+func EscapeCsvCell(value string) string {
+	if startsFormula(strings.TrimLeft(value, "'")) {
+		return "'" + value
+	}
+	return value
+}
+
+// UnescapeCsvCell undoes EscapeCsvCell, so an exported file imports back to the same values.
+func UnescapeCsvCell(value string) string {
+	if strings.HasPrefix(value, "'") && startsFormula(strings.TrimLeft(value, "'")) {
+		return value[1:]
+	}
+	return value
+}
+
+func startsFormula(value string) bool {
+	return value != "" && strings.ContainsRune(formulaStarts, rune(value[0]))
+}
@@ -1,6 +1,8 @@
 package helper
 
 import (
+	"strings"
+
 	"github.com/mrakhaf/golang-restful-api/model/domain"
 	"github.com/mrakhaf/golang-restful-api/model/web"
 )
@@ -12,10 +14,53 @@ func ToCategoryResponse(category domain.Category) web.CategoryResponse {
 	}
 }
 
-func ToCategoryResponses(categories []domain.Category) []web.CategoryResponse {
-	var categoriesResponses []web.CategoryResponse
-	for _, category := range categories {
-		categoriesResponses = append(categoriesResponses, ToCategoryResponse(category))
+// ToResponses converts every item with convert, keeping a nil slice nil.
+func ToResponses[T any, R any](items []T, convert func(T) R) []R {
+	var responses []R
+	for _, item := range items {
+		responses = append(responses, convert(item))
+	}
+	return responses
+}
+
+func ToProductResponse(product domain.Product) web.ProductResponse {
+	return web.ProductResponse{
+		Id:         product.Id,
+		CategoryId: product.CategoryId,
+		Name:       product.Name,
+		Price:      product.Price,
+	}
+}
+
+func ToProductResponses(products []domain.Product) []web.ProductResponse {
+	var productResponses []web.ProductResponse
+	for _, product := range products {
+		productResponses = append(productResponses, ToProductResponse(product))
+	}
+	return productResponses
+}
+
+func ToApiKeyResponse(apiKey domain.ApiKey) web.ApiKeyResponse {
+	return web.ApiKeyResponse{
+		Id:         apiKey.Id,
+		Name:       apiKey.Name,
+		Owner:      apiKey.Owner,
+		Prefix:     apiKey.Prefix,
+		Scopes:     strings.Fields(apiKey.Scopes),
+		CreatedAt:  apiKey.CreatedAt,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		Revoked:    apiKey.Revoked,
+	}
+}
+
+func ToOauthClientResponse(client domain.OauthClient) web.OauthClientResponse {
+	return web.OauthClientResponse{
+		Id:        client.Id,
+		ClientId:  client.ClientId,
+		Name:      client.Name,
+		Scopes:    strings.Fields(client.Scopes),
+		CreatedAt: client.CreatedAt,
+		Revoked:   client.Revoked,
 	}
-	return categoriesResponses
 }
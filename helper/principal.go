@@ -0,0 +1,20 @@
+package helper
+
+import (
+	"context"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+type principalContextKey struct{}
+
+// WithPrincipal stores the authenticated caller and scopes the context to its tenant.
+func WithPrincipal(ctx context.Context, principal web.Principal) context.Context {
+	ctx = context.WithValue(ctx, principalContextKey{}, principal)
+	return WithTenant(ctx, principal.TenantId)
+}
+
+func PrincipalFromContext(ctx context.Context) (web.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(web.Principal)
+	return principal, ok
+}
@@ -0,0 +1,36 @@
+package helper
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+type requestIdContextKey struct{}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+// RequestIdFromContext returns "" outside of a request, e.g. in background jobs.
+func RequestIdFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdContextKey{}).(string)
+	return requestId
+}
+
+func NewRequestId() string {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	PanicIfError(err)
+	return hex.EncodeToString(id)
+}
+
+// Logger returns the default logger with the request id of ctx attached, so repositories and services
+// log lines that are found again from the id a client reports.
+func Logger(ctx context.Context) *slog.Logger {
+	if requestId := RequestIdFromContext(ctx); requestId != "" {
+		return slog.Default().With("request_id", requestId)
+	}
+	return slog.Default()
+}
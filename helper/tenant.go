@@ -0,0 +1,21 @@
+package helper
+
+import (
+	"context"
+	"errors"
+)
+
+type tenantContextKey struct{}
+
+func WithTenant(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantId)
+}
+
+// TenantFromContext panics when no tenant was resolved, so a query can never run unscoped.
+func TenantFromContext(ctx context.Context) string {
+	tenantId, ok := ctx.Value(tenantContextKey{}).(string)
+	if !ok || tenantId == "" {
+		panic(errors.New("tenant is missing from context"))
+	}
+	return tenantId
+}
@@ -0,0 +1,98 @@
+package helper
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+// universalTranslator holds the validation messages of every supported locale, English is the fallback.
+var universalTranslator = ut.New(en.New(), en.New(), id.New())
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+)
+
+// Validator returns the validator shared by every service. It names fields by their JSON name and has the
+// English and Indonesian messages, which can only be registered once per locale.
+func Validator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+
+		english, _ := universalTranslator.GetTranslator("en")
+		PanicIfError(enTranslations.RegisterDefaultTranslations(validate, english))
+		indonesian, _ := universalTranslator.GetTranslator("id")
+		PanicIfError(idTranslations.RegisterDefaultTranslations(validate, indonesian))
+	})
+	return validate
+}
+
+// Translator picks the best supported locale of an Accept-Language header like "id-ID,id;q=0.9,en;q=0.8".
+func Translator(acceptLanguage string) ut.Translator {
+	type language struct {
+		tag     string
+		quality float64
+	}
+	var languages []language
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		// the locales are registered by language only, so "id-ID" is looked up as "id"
+		tag, _, _ = strings.Cut(strings.ToLower(tag), "-")
+		if tag != "" && quality > 0 {
+			languages = append(languages, language{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	tags := make([]string, len(languages))
+	for i, language := range languages {
+		tags[i] = language.tag
+	}
+	translator, _ := universalTranslator.FindTranslator(tags...)
+	return translator
+}
+
+// ToValidationErrors turns the errors of the validator into the ones sent to clients, with messages in the locale of translator.
+func ToValidationErrors(err validator.ValidationErrors, translator ut.Translator) []web.ValidationError {
+	validationErrors := make([]web.ValidationError, len(err))
+	for i, fieldError := range err {
+		// the namespace starts with the struct name, the rest is the path of JSON names, like "scopes[0]"
+		_, field, _ := strings.Cut(fieldError.Namespace(), ".")
+		validationErrors[i] = web.ValidationError{
+			Field:   field,
+			Rule:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: fieldError.Translate(translator),
+		}
+	}
+	return validationErrors
+}
@@ -1,33 +1,153 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/go-playground/validator/v10"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/mrakhaf/golang-restful-api/config"
 	"github.com/mrakhaf/golang-restful-api/controller"
+	"github.com/mrakhaf/golang-restful-api/exeption"
 	"github.com/mrakhaf/golang-restful-api/helper"
 	"github.com/mrakhaf/golang-restful-api/middleware"
 	"github.com/mrakhaf/golang-restful-api/repository"
 	"github.com/mrakhaf/golang-restful-api/service"
 )
 
+// shutdownTimeout is how long in-flight requests may take to finish on SIGINT or SIGTERM.
+const shutdownTimeout = 30 * time.Second
+
 func main() {
-	db := config.NewDB()
-	validate := validator.New()
+	appConfig, err := config.LoadAppConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		// the configured logger is not known yet, so this goes through the default one
+		slog.Error("cannot start", "error", err)
+		os.Exit(2)
+	}
+	auth := appConfig.Auth
+
+	logger := config.NewLogger(appConfig.Log)
+	slog.SetDefault(logger)
+	exeption.Debug = appConfig.Errors.Debug
+	exeption.ProblemJson = appConfig.Errors.Format == "problem"
+
+	tlsSettings := appConfig.Tls
+	var tlsConfig *tls.Config
+	if tlsSettings.Enabled() {
+		// built before anything runs, so a bad certificate ends the start instead of the serving goroutine
+		tlsConfig, err = config.LoadTlsConfig(tlsSettings)
+		if err != nil {
+			logger.Error("cannot start", "error", err)
+			os.Exit(2)
+		}
+	}
+
+	db := config.NewDB(appConfig.Database)
+	validate := helper.Validator()
 	categoryRepository := repository.NewCategoryRepository()
-	serviceCategory := service.NewCategoryService(categoryRepository, db, validate)
+	productRepository := repository.NewProductRepository()
+	serviceCategory := service.NewCategoryService(categoryRepository, productRepository, db, validate)
 	categoryController := controller.NewCategoryController(serviceCategory)
+	productService := service.NewProductService(productRepository, categoryRepository, db, validate)
+	productController := controller.NewProductController(productService)
+	backupService := service.NewBackupService(categoryRepository, productRepository, db, validate)
+	backupController := controller.NewBackupController(backupService)
+	apiKeyCache := middleware.NewApiKeyCache(auth.ApiKeyCacheTtl, auth.ApiKeyCacheSize)
+	apiKeyService := service.NewApiKeyService(repository.NewApiKeyRepository(), db, validate, apiKeyCache)
+	apiKeyController := controller.NewApiKeyController(apiKeyService)
+
+	oauthService := service.NewOauthService(repository.NewOauthClientRepository(), repository.NewOauthTokenRepository(), db, validate, config.NewOauthTokenConfig(auth))
+	oauthController := controller.NewOauthController(oauthService)
 
-	router := config.NewRouter(categoryController)
+	usageQuota, keyUsageQuotas := config.NewUsageQuotas(appConfig.Limits)
+	usageService := service.NewUsageService(repository.NewUsageRepository(), db, usageQuota, keyUsageQuotas)
+	usageController := controller.NewUsageController(usageService)
+	flushContext, stopFlushing := context.WithCancel(context.Background())
+	flushed := make(chan struct{})
+	go func() {
+		usageService.FlushEvery(flushContext, time.Minute)
+		close(flushed)
+	}()
+
+	authenticators := []middleware.Authenticator{
+		middleware.NewApiKeyAuthenticator(config.NewApiKeyTenants(auth), apiKeyService, apiKeyCache),
+		middleware.NewOauthAuthenticator(oauthService),
+	}
+	if hmacKeys := config.NewHmacKeys(auth); len(hmacKeys) > 0 {
+		authenticators = append(authenticators, middleware.NewHmacAuthenticator(hmacKeys, auth.HmacClockSkew, auth.HmacNonceCacheSize))
+	}
+	if jwtConfig, ok := config.NewJwtConfig(auth); ok {
+		authenticators = append(authenticators, middleware.NewJwtAuthenticator(jwtConfig))
+	}
+	if tlsConfig != nil && tlsSettings.ClientCaFile != "" {
+		// a verified client certificate is the strongest credential, so it is checked first
+		authenticators = append([]middleware.Authenticator{middleware.NewClientCertAuthenticator(config.NewClientCertIdentities(tlsSettings))}, authenticators...)
+	}
+
+	rateLimits := config.NewRateLimits(appConfig.Limits)
+	rateLimit := middleware.NewRateLimitMiddleware(rateLimits.Default, rateLimits.Keys, middleware.DefaultMaxBuckets)
+	// without a principal yet, this one is keyed by the client IP and keeps failed authentications in check
+	clientIpRateLimit := middleware.NewRateLimitMiddleware(rateLimits.ClientIp, nil, middleware.DefaultMaxBuckets)
+
+	chains := config.RouteChains{
+		Public:    middleware.NewChain(rateLimit),
+		Protected: middleware.NewChain(clientIpRateLimit, middleware.NewAuthMiddleware(authenticators...), rateLimit),
+		Metered:   middleware.NewChain(middleware.NewUsageMiddleware(usageService)),
+	}
+	router := config.NewRouter(chains, controller.NewHealthController(db), categoryController, productController, backupController, apiKeyController, oauthController, usageController)
+
+	stack := middleware.NewChain(middleware.NewRequestIdMiddleware(), middleware.NewAccessLogMiddleware(logger, config.NewAccessLogConfig(appConfig.Log)), middleware.NewRecoverMiddleware())
+	if corsConfig, ok := config.NewCorsConfig(appConfig.Cors); ok {
+		// preflight requests carry no credentials, so they are answered before any route runs
+		stack = stack.Append(middleware.NewCorsMiddleware(corsConfig))
+	}
+	handler := stack.Then(router)
 
 	server := http.Server{
-		Addr:    "localhost:3000",
-		Handler: middleware.NewAuthMiddleware(router),
+		Addr:              appConfig.Server.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: appConfig.Server.ReadHeaderTimeout,
+		ReadTimeout:       appConfig.Server.ReadTimeout,
+		WriteTimeout:      appConfig.Server.WriteTimeout,
+		IdleTimeout:       appConfig.Server.IdleTimeout,
+		TLSConfig:         tlsConfig,
 	}
 
-	err := server.ListenAndServe()
-	helper.PanicIfError(err)
+	served := make(chan error, 1)
+	go func() {
+		if tlsConfig != nil {
+			served <- server.ListenAndServeTLS("", "")
+		} else {
+			served <- server.ListenAndServe()
+		}
+	}()
 
+	stopping, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err = <-served:
+	case <-stopping.Done():
+		logger.Info("shutting down")
+		shutdownContext, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		err = server.Shutdown(shutdownContext)
+		cancel()
+	}
+
+	// the requests have finished, so the last flush holds all of their counts
+	stopFlushing()
+	<-flushed
+	if !errors.Is(err, http.ErrServerClosed) {
+		helper.PanicIfError(err)
+	}
 }
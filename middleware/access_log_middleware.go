@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+// DefaultRedactHeaders carry credentials, their values never reach the access log.
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-API-Key", "X-Signature"}
+
+type AccessLogConfig struct {
+	// SampleRate is the share of successful requests logged, from 0 to 1. Failed requests are always logged.
+	SampleRate    float64
+	RedactHeaders []string
+}
+
+type accessLogMiddleware struct {
+	logger *slog.Logger
+	config AccessLogConfig
+}
+
+// NewAccessLogMiddleware writes one record per request through logger: at error level for 5xx, warn for
+// 4xx and info otherwise. It runs behind the request id middleware and in front of the router.
+func NewAccessLogMiddleware(logger *slog.Logger, config AccessLogConfig) Middleware {
+	middleware := &accessLogMiddleware{logger: logger, config: config}
+	return serveWith(middleware.serve)
+}
+
+// accessLogEntry collects what is only known deeper in the chain, like the matched route and the principal.
+type accessLogEntry struct {
+	route     string
+	principal string
+	tenantId  string
+}
+
+type accessLogContextKey struct{}
+
+func (middleware *accessLogMiddleware) serve(writer http.ResponseWriter, request *http.Request, next http.Handler) {
+	start := time.Now()
+	entry := &accessLogEntry{}
+	recorder := &statusRecorder{ResponseWriter: writer}
+
+	next.ServeHTTP(recorder, request.WithContext(context.WithValue(request.Context(), accessLogContextKey{}, entry)))
+
+	status := recorder.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	level := slog.LevelInfo
+	if status >= 500 {
+		level = slog.LevelError
+	} else if status >= 400 {
+		level = slog.LevelWarn
+	}
+	ctx := request.Context()
+	if !middleware.logger.Enabled(ctx, level) {
+		return
+	}
+	if status < 400 && middleware.config.SampleRate < 1 && rand.Float64() >= middleware.config.SampleRate {
+		return
+	}
+
+	clientIp, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		clientIp = request.RemoteAddr
+	}
+
+	middleware.logger.LogAttrs(ctx, level, "request",
+		slog.String("method", request.Method),
+		slog.String("route", entry.route),
+		slog.Int("status", status),
+		slog.Int64("bytes", recorder.bytes),
+		slog.Duration("latency", time.Since(start)),
+		slog.String("client_ip", clientIp),
+		slog.String("principal", entry.principal),
+		slog.String("tenant", entry.tenantId),
+		slog.String("request_id", helper.RequestIdFromContext(ctx)),
+		slog.Any("headers", middleware.headers(request.Header)),
+	)
+}
+
+func (middleware *accessLogMiddleware) headers(header http.Header) slog.Value {
+	attrs := make([]slog.Attr, 0, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		for _, redacted := range middleware.config.RedactHeaders {
+			if strings.EqualFold(name, redacted) {
+				value = "[REDACTED]"
+			}
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// RoutePattern records the pattern a route was registered with, since httprouter only passes the params on.
+func RoutePattern(pattern string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if entry, ok := request.Context().Value(accessLogContextKey{}).(*accessLogEntry); ok {
+				entry.route = pattern
+			}
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+func recordPrincipal(request *http.Request, principal web.Principal) {
+	if entry, ok := request.Context().Value(accessLogContextKey{}).(*accessLogEntry); ok {
+		entry.principal = service.CredentialKey(principal)
+		entry.tenantId = principal.TenantId
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(content []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	n, err := recorder.ResponseWriter.Write(content)
+	recorder.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to flush a streamed export.
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
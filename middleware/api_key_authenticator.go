@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+// ApiKeyAuthenticator accepts the X-API-Key header. Tenants maps static keys to their tenant and grants them every scope,
+// any other key is looked up through ApiKeyService and Cache. ApiKeyService may be nil to accept static keys only.
+type ApiKeyAuthenticator struct {
+	Tenants       map[string]string
+	ApiKeyService service.ApiKeyService
+	Cache         *ApiKeyCache
+}
+
+func NewApiKeyAuthenticator(tenants map[string]string, apiKeyService service.ApiKeyService, cache *ApiKeyCache) *ApiKeyAuthenticator {
+	return &ApiKeyAuthenticator{Tenants: tenants, ApiKeyService: apiKeyService, Cache: cache}
+}
+
+var errInvalidApiKey = errors.New("api key is invalid")
+
+func (authenticator *ApiKeyAuthenticator) Authenticate(request *http.Request) (web.Principal, bool, error) {
+	key := request.Header.Get("X-API-Key")
+	if key == "" {
+		return web.Principal{}, false, nil
+	}
+
+	if tenantId, ok := authenticator.Tenants[key]; ok {
+		// the id tells static keys apart, for rate limits and usage, without revealing them
+		id := "static-" + service.HashApiKey(key)[:12]
+		return web.Principal{Kind: "api_key", Id: id, Name: "static", TenantId: tenantId, Scopes: []string{"*"}}, true, nil
+	}
+
+	if authenticator.ApiKeyService == nil {
+		return web.Principal{}, true, errInvalidApiKey
+	}
+
+	keyHash := service.HashApiKey(key)
+	if principal, valid, found := authenticator.Cache.Get(keyHash); found {
+		if !valid {
+			return web.Principal{}, true, errInvalidApiKey
+		}
+		return principal, true, nil
+	}
+
+	principal, err := authenticator.ApiKeyService.Authenticate(request.Context(), key)
+	authenticator.Cache.Put(keyHash, principal, err == nil)
+	return principal, true, err
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+// ApiKeyCache remembers lookups by key hash for a short time, so a busy key costs one query per TTL
+// instead of one per request. Revoking or rotating a key deletes its entry on this instance,
+// other instances accept it until their entry expires. A nil cache disables caching.
+type ApiKeyCache struct {
+	TTL        time.Duration
+	MaxEntries int
+
+	mutex   sync.Mutex
+	entries map[string]apiKeyCacheEntry
+}
+
+type apiKeyCacheEntry struct {
+	principal web.Principal
+	valid     bool
+	expiresAt time.Time
+}
+
+func NewApiKeyCache(ttl time.Duration, maxEntries int) *ApiKeyCache {
+	return &ApiKeyCache{TTL: ttl, MaxEntries: maxEntries, entries: map[string]apiKeyCacheEntry{}}
+}
+
+// Get returns the cached principal and whether the key was valid, and false as last value on a miss.
+func (cache *ApiKeyCache) Get(keyHash string) (web.Principal, bool, bool) {
+	if cache == nil {
+		return web.Principal{}, false, false
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok := cache.entries[keyHash]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return web.Principal{}, false, false
+	}
+	return entry.principal, entry.valid, true
+}
+
+func (cache *ApiKeyCache) Put(keyHash string, principal web.Principal, valid bool) {
+	if cache == nil {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := time.Now()
+	if len(cache.entries) >= cache.MaxEntries {
+		for cachedKey, entry := range cache.entries {
+			if now.After(entry.expiresAt) {
+				delete(cache.entries, cachedKey)
+			}
+		}
+		// still full of live entries, most likely someone is guessing keys
+		if len(cache.entries) >= cache.MaxEntries {
+			cache.entries = map[string]apiKeyCacheEntry{}
+		}
+	}
+
+	cache.entries[keyHash] = apiKeyCacheEntry{principal: principal, valid: valid, expiresAt: now.Add(cache.TTL)}
+}
+
+func (cache *ApiKeyCache) Delete(keyHash string) {
+	if cache == nil {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	delete(cache.entries, keyHash)
+}
@@ -1,34 +1,51 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/mrakhaf/golang-restful-api/exeption"
 	"github.com/mrakhaf/golang-restful-api/helper"
 	"github.com/mrakhaf/golang-restful-api/model/web"
 )
 
-type AuthMiddleware struct {
-	Handler http.Handler
+type authMiddleware struct {
+	authenticators []Authenticator
 }
 
-func NewAuthMiddleware(handler http.Handler) *AuthMiddleware {
-	return &AuthMiddleware{Handler: handler}
+// NewAuthMiddleware tries the authenticators in order; the first one that finds its credential on the request decides.
+func NewAuthMiddleware(authenticators ...Authenticator) Middleware {
+	middleware := &authMiddleware{authenticators: authenticators}
+	return serveWith(middleware.serve)
 }
 
-func (middleware *AuthMiddleware) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	if "rahasia" == request.Header.Get("X-API-Key") {
+func (middleware *authMiddleware) serve(writer http.ResponseWriter, request *http.Request, next http.Handler) {
+	principal, present, err := middleware.authenticate(request)
+	if present && err == nil {
 		//ok
-		middleware.Handler.ServeHTTP(writer, request)
+		recordPrincipal(request, principal)
+		ctx := helper.WithPrincipal(request.Context(), principal)
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	} else if errors.Is(err, ErrNonceStoreFull) {
+		// the credential may be fine, so the client is told to retry rather than that it is refused
+		var full *NonceStoreFullError
+		if errors.As(err, &full) {
+			writer.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(full.RetryAfter)))
+		}
+		exeption.WriteError(writer, request, exeption.RateLimited, err.Error())
 	} else {
 		//error
-		writer.Header().Set("Content-Type", "application/json")
-		writer.WriteHeader(http.StatusUnauthorized)
+		exeption.WriteError(writer, request, exeption.Unauthorized, nil)
+	}
+}
 
-		webResponse := web.WebResponse{
-			Code:   http.StatusUnauthorized,
-			Status: "UNAUTHORIZED",
+func (middleware *authMiddleware) authenticate(request *http.Request) (web.Principal, bool, error) {
+	for _, authenticator := range middleware.authenticators {
+		principal, present, err := authenticator.Authenticate(request)
+		if present {
+			return principal, true, err
 		}
-
-		helper.WriteToResponseBody(writer, webResponse)
 	}
+	return web.Principal{}, false, nil
 }
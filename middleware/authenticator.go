@@ -0,0 +1,14 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+// Authenticator resolves the caller of a request from one kind of credential.
+// present is false when the request does not carry that kind of credential at all,
+// so the next authenticator gets a chance; err is set when it does but it is invalid.
+type Authenticator interface {
+	Authenticate(request *http.Request) (principal web.Principal, present bool, err error)
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import "net/http"
+
+// Middleware wraps a handler with a cross-cutting concern, such as the one NewAuthMiddleware returns.
+type Middleware func(next http.Handler) http.Handler
+
+// Chain is an ordered middleware stack, the first middleware runs outermost.
+type Chain []Middleware
+
+func NewChain(middlewares ...Middleware) Chain {
+	return Chain(middlewares)
+}
+
+// Append returns a new chain with middlewares running after the ones of chain, leaving chain unchanged.
+func (chain Chain) Append(middlewares ...Middleware) Chain {
+	return append(append(Chain{}, chain...), middlewares...)
+}
+
+func (chain Chain) Then(handler http.Handler) http.Handler {
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	return handler
+}
+
+// serveWith adapts a serve method that takes the next handler to a Middleware.
+func serveWith(serve func(writer http.ResponseWriter, request *http.Request, next http.Handler)) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			serve(writer, request, next)
+		})
+	}
+}
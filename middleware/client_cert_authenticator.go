@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"crypto/x509"
+	"net/http"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+// ClientCertIdentity is the tenant and scopes granted to a client certificate name.
+type ClientCertIdentity struct {
+	TenantId string   `json:"tenant"`
+	Scopes   []string `json:"scopes"`
+}
+
+// ClientCertAuthenticator accepts TLS client certificates that were verified against the client CA bundle.
+// Identities is keyed by a URI, DNS or email SAN, or else the subject common name, tried in that order.
+// Certificates without a known name are reported absent, so other credentials can still be used.
+type ClientCertAuthenticator struct {
+	Identities map[string]ClientCertIdentity
+}
+
+func NewClientCertAuthenticator(identities map[string]ClientCertIdentity) *ClientCertAuthenticator {
+	return &ClientCertAuthenticator{Identities: identities}
+}
+
+func (authenticator *ClientCertAuthenticator) Authenticate(request *http.Request) (web.Principal, bool, error) {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 {
+		return web.Principal{}, false, nil
+	}
+
+	certificate := request.TLS.VerifiedChains[0][0]
+	for _, name := range certificateNames(certificate) {
+		if identity, ok := authenticator.Identities[name]; ok {
+			return web.Principal{
+				Kind:     "client_cert",
+				Id:       name,
+				Name:     certificate.Subject.String(),
+				TenantId: identity.TenantId,
+				Scopes:   identity.Scopes,
+			}, true, nil
+		}
+	}
+	return web.Principal{}, false, nil
+}
+
+func certificateNames(certificate *x509.Certificate) []string {
+	var names []string
+	for _, uri := range certificate.URIs {
+		names = append(names, uri.String())
+	}
+	names = append(names, certificate.DNSNames...)
+	names = append(names, certificate.EmailAddresses...)
+	if certificate.Subject.CommonName != "" {
+		names = append(names, certificate.Subject.CommonName)
+	}
+	return names
+}
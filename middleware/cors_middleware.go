@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CorsConfig struct {
+	// AllowedOrigins are exact origins like "https://admin.example.com", patterns like
+	// "https://*.example.com" matching any subdomain, or "*" for every origin.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+type corsMiddleware struct {
+	config CorsConfig
+}
+
+// NewCorsMiddleware answers preflight requests itself and adds the CORS headers to every other response.
+// It must run in front of the router so preflights never reach the auth middleware.
+func NewCorsMiddleware(config CorsConfig) Middleware {
+	middleware := &corsMiddleware{config: config}
+	return serveWith(middleware.serve)
+}
+
+func (middleware *corsMiddleware) serve(writer http.ResponseWriter, request *http.Request, next http.Handler) {
+	origin := request.Header.Get("Origin")
+	if origin == "" {
+		next.ServeHTTP(writer, request)
+		return
+	}
+
+	header := writer.Header()
+	header.Add("Vary", "Origin")
+
+	if request.Method == http.MethodOptions && request.Header.Get("Access-Control-Request-Method") != "" {
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		// a refused preflight gets no CORS headers, so the browser blocks the actual request
+		if middleware.allowOrigin(origin) && middleware.allowPreflight(request) {
+			middleware.writeOrigin(header, origin)
+			header.Set("Access-Control-Allow-Methods", strings.Join(middleware.config.AllowedMethods, ", "))
+			if len(middleware.config.AllowedHeaders) > 0 {
+				header.Set("Access-Control-Allow-Headers", strings.Join(middleware.config.AllowedHeaders, ", "))
+			}
+			if middleware.config.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", strconv.Itoa(int(middleware.config.MaxAge.Seconds())))
+			}
+		}
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if middleware.allowOrigin(origin) {
+		middleware.writeOrigin(header, origin)
+		if len(middleware.config.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(middleware.config.ExposedHeaders, ", "))
+		}
+	}
+	next.ServeHTTP(writer, request)
+}
+
+func (middleware *corsMiddleware) writeOrigin(header http.Header, origin string) {
+	anyOrigin := false
+	for _, allowed := range middleware.config.AllowedOrigins {
+		anyOrigin = anyOrigin || allowed == "*"
+	}
+	if anyOrigin {
+		header.Set("Access-Control-Allow-Origin", "*")
+		// credentials are never granted to every origin, config validation refuses that combination
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	if middleware.config.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (middleware *corsMiddleware) allowOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range middleware.config.AllowedOrigins {
+		if matchOrigin(strings.ToLower(allowed), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (middleware *corsMiddleware) allowPreflight(request *http.Request) bool {
+	method := request.Header.Get("Access-Control-Request-Method")
+	allowed := false
+	for _, allowedMethod := range middleware.config.AllowedMethods {
+		if strings.EqualFold(allowedMethod, method) {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return false
+	}
+
+	for _, requested := range strings.Split(request.Header.Get("Access-Control-Request-Headers"), ",") {
+		requested = strings.TrimSpace(requested)
+		if requested == "" {
+			continue
+		}
+		allowed = false
+		for _, allowedHeader := range middleware.config.AllowedHeaders {
+			if allowedHeader == "*" || strings.EqualFold(allowedHeader, requested) {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// matchOrigin matches origin against an allowed origin, where "https://*.example.com" matches
+// "https://admin.example.com" and "https://a.b.example.com" but not "https://example.com".
+func matchOrigin(allowed string, origin string) bool {
+	if allowed == "*" || allowed == origin {
+		return true
+	}
+	prefix, suffix, ok := strings.Cut(allowed, "*.")
+	if !ok {
+		return false
+	}
+	if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, "."+suffix) {
+		return false
+	}
+	subdomain := strings.TrimSuffix(strings.TrimPrefix(origin, prefix), "."+suffix)
+	return subdomain != "" && !strings.ContainsAny(subdomain, "/:@")
+}
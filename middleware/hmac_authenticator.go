@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/signing"
+)
+
+// HmacKey is a shared secret for signed requests and the tenant and scopes it grants.
+type HmacKey struct {
+	Secret   []byte
+	TenantId string
+	Scopes   []string
+}
+
+// HmacAuthenticator accepts requests signed with signing.Sign. The timestamp must be within Window
+// of the server clock and every nonce is accepted once.
+type HmacAuthenticator struct {
+	Keys         map[string]HmacKey
+	Window       time.Duration
+	MaxBodyBytes int64
+	Nonces       *NonceStore
+}
+
+// NewHmacAuthenticator shares maxNonces evenly between the keys, so one key using up its share
+// does not lock out the others.
+func NewHmacAuthenticator(keys map[string]HmacKey, window time.Duration, maxNonces int) *HmacAuthenticator {
+	noncesPerKey := maxNonces
+	if len(keys) > 0 {
+		noncesPerKey = max(maxNonces/len(keys), 1)
+	}
+	return &HmacAuthenticator{
+		Keys:         keys,
+		Window:       window,
+		MaxBodyBytes: 10 << 20,
+		// a timestamp is accepted from window before to window after it was signed
+		Nonces: NewNonceStore(2*window, maxNonces, noncesPerKey),
+	}
+}
+
+func (authenticator *HmacAuthenticator) Authenticate(request *http.Request) (web.Principal, bool, error) {
+	signature := request.Header.Get(signing.HeaderSignature)
+	if signature == "" {
+		return web.Principal{}, false, nil
+	}
+
+	keyId := request.Header.Get(signing.HeaderKeyId)
+	key, ok := authenticator.Keys[keyId]
+	if !ok {
+		return web.Principal{}, true, errors.New("unknown signing key")
+	}
+
+	timestamp := request.Header.Get(signing.HeaderTimestamp)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return web.Principal{}, true, errors.New("timestamp is not a unix time")
+	}
+	skew := time.Since(time.Unix(seconds, 0))
+	if skew > authenticator.Window || skew < -authenticator.Window {
+		return web.Principal{}, true, errors.New("timestamp is outside the window")
+	}
+
+	nonce := request.Header.Get(signing.HeaderNonce)
+	if nonce == "" {
+		return web.Principal{}, true, errors.New("nonce is missing")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(request.Body, authenticator.MaxBodyBytes+1))
+	if err != nil {
+		return web.Principal{}, true, err
+	}
+	if int64(len(body)) > authenticator.MaxBodyBytes {
+		return web.Principal{}, true, errors.New("body is too large to verify")
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	stringToSign := signing.StringToSign(request.Method, request.URL.EscapedPath(), request.URL.Query(), signing.BodyHash(body), timestamp, nonce)
+	if !hmac.Equal([]byte(signature), []byte(signing.Signature(key.Secret, stringToSign))) {
+		return web.Principal{}, true, errors.New("signature does not match")
+	}
+
+	// only verified requests use up room in the nonce store
+	if err := authenticator.Nonces.Add(keyId, nonce); err != nil {
+		return web.Principal{}, true, err
+	}
+
+	return web.Principal{
+		Kind:     "hmac",
+		Id:       keyId,
+		Name:     keyId,
+		TenantId: key.TenantId,
+		Scopes:   key.Scopes,
+	}, true, nil
+}
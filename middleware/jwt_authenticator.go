@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+type JwtConfig struct {
+	// Secret verifies HS256 tokens.
+	Secret []byte
+	// Keys verify RS256/ES256 tokens, selected by the kid header; see LoadJwks.
+	Keys      map[string]crypto.PublicKey
+	Issuer    string
+	Audience  string
+	ClockSkew time.Duration
+	// TenantClaim names the claim holding the tenant, "tenant" when empty.
+	TenantClaim string
+}
+
+// JwtAuthenticator accepts "Authorization: Bearer <jwt>" and puts the verified claims into the principal.
+type JwtAuthenticator struct {
+	Config JwtConfig
+	parser *jwt.Parser
+}
+
+func NewJwtAuthenticator(config JwtConfig) *JwtAuthenticator {
+	if config.TenantClaim == "" {
+		config.TenantClaim = "tenant"
+	}
+
+	var methods []string
+	if len(config.Secret) > 0 {
+		methods = append(methods, "HS256")
+	}
+	if len(config.Keys) > 0 {
+		methods = append(methods, "RS256", "RS384", "RS512", "ES256", "ES384", "ES512")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(config.ClockSkew),
+		jwt.WithExpirationRequired(),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &JwtAuthenticator{Config: config, parser: jwt.NewParser(options...)}
+}
+
+func (authenticator *JwtAuthenticator) Authenticate(request *http.Request) (web.Principal, bool, error) {
+	scheme, token, found := strings.Cut(request.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return web.Principal{}, false, nil
+	}
+
+	principal, err := authenticator.Verify(strings.TrimSpace(token))
+	return principal, true, err
+}
+
+// Verify checks the signature, exp, nbf, iss and aud of a token and returns the caller it names.
+func (authenticator *JwtAuthenticator) Verify(token string) (web.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := authenticator.parser.ParseWithClaims(token, claims, authenticator.key)
+	if err != nil {
+		return web.Principal{}, err
+	}
+
+	subject, _ := claims.GetSubject()
+	tenantId, _ := claims[authenticator.Config.TenantClaim].(string)
+	if subject == "" || tenantId == "" {
+		return web.Principal{}, errors.New("token has no subject or tenant")
+	}
+
+	return web.Principal{
+		Kind:     "jwt",
+		Id:       subject,
+		Name:     subject,
+		TenantId: tenantId,
+		Scopes:   scopesClaim(claims),
+		Claims:   claims,
+	}, nil
+}
+
+// scopesClaim reads the space separated "scope" claim of RFC 8693, falling back to a "scp" list.
+func scopesClaim(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+
+	switch scp := claims["scp"].(type) {
+	case string:
+		return strings.Fields(scp)
+	case []interface{}:
+		var scopes []string
+		for _, scope := range scp {
+			if scope, ok := scope.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+		return scopes
+	}
+	return nil
+}
+
+func (authenticator *JwtAuthenticator) key(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		return authenticator.Config.Secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := authenticator.Config.Keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNonceUsed = errors.New("nonce was already used")
+	// ErrNonceStoreFull refuses a request that may well be genuine, the client should retry once old nonces expired.
+	ErrNonceStoreFull = errors.New("too many signed requests, retry later")
+)
+
+// NonceStoreFullError is ErrNonceStoreFull with the time until the oldest nonce in the way expires.
+type NonceStoreFullError struct {
+	RetryAfter time.Duration
+}
+
+func (err *NonceStoreFullError) Error() string {
+	return ErrNonceStoreFull.Error()
+}
+
+func (err *NonceStoreFullError) Is(target error) bool {
+	return target == ErrNonceStoreFull
+}
+
+type nonceId struct {
+	key   string
+	nonce string
+}
+
+type nonceEntry struct {
+	nonceId
+	expires time.Time
+}
+
+// NonceStore remembers the nonces of every key for TTL so a signed request cannot be replayed. It holds
+// at most MaxEntries live nonces, and at most MaxEntriesPerKey of one key so a busy key cannot crowd out
+// the others, and refuses new ones when full rather than forgetting one that may be replayed.
+type NonceStore struct {
+	TTL              time.Duration
+	MaxEntries       int
+	MaxEntriesPerKey int
+
+	mutex sync.Mutex
+	seen  map[nonceId]bool
+	// order lists the nonces by expiry, which is their insertion order since TTL is fixed
+	order []nonceEntry
+	// keyOrder lists the expiries of the nonces of each key the same way
+	keyOrder map[string][]time.Time
+}
+
+func NewNonceStore(ttl time.Duration, maxEntries int, maxEntriesPerKey int) *NonceStore {
+	return &NonceStore{
+		TTL:              ttl,
+		MaxEntries:       maxEntries,
+		MaxEntriesPerKey: maxEntriesPerKey,
+		seen:             map[nonceId]bool{},
+		keyOrder:         map[string][]time.Time{},
+	}
+}
+
+// Add records nonce for key, or returns ErrNonceUsed when it was already seen and a *NonceStoreFullError
+// when there is no room.
+func (store *NonceStore) Add(key string, nonce string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	for len(store.order) > 0 && now.After(store.order[0].expires) {
+		oldest := store.order[0]
+		store.order = store.order[1:]
+		delete(store.seen, oldest.nonceId)
+		if expiries := store.keyOrder[oldest.key][1:]; len(expiries) > 0 {
+			store.keyOrder[oldest.key] = expiries
+		} else {
+			delete(store.keyOrder, oldest.key)
+		}
+	}
+
+	id := nonceId{key: key, nonce: nonce}
+	if store.seen[id] {
+		return ErrNonceUsed
+	}
+	if expiries := store.keyOrder[key]; len(expiries) >= store.MaxEntriesPerKey {
+		return &NonceStoreFullError{RetryAfter: expiries[0].Sub(now)}
+	}
+	if len(store.order) >= store.MaxEntries {
+		return &NonceStoreFullError{RetryAfter: store.order[0].expires.Sub(now)}
+	}
+
+	expires := now.Add(store.TTL)
+	store.seen[id] = true
+	store.order = append(store.order, nonceEntry{nonceId: id, expires: expires})
+	store.keyOrder[key] = append(store.keyOrder[key], expires)
+	return nil
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+// OauthAuthenticator accepts the bearer access tokens issued by /oauth/token. Bearer tokens from another
+// issuer are reported absent, so a JwtAuthenticator placed after it still gets to verify them.
+type OauthAuthenticator struct {
+	OauthService service.OauthService
+}
+
+func NewOauthAuthenticator(oauthService service.OauthService) *OauthAuthenticator {
+	return &OauthAuthenticator{OauthService: oauthService}
+}
+
+func (authenticator *OauthAuthenticator) Authenticate(request *http.Request) (web.Principal, bool, error) {
+	scheme, token, found := strings.Cut(request.Header.Get("Authorization"), " ")
+	token = strings.TrimSpace(token)
+	if !found || !strings.EqualFold(scheme, "Bearer") || !authenticator.OauthService.Issued(token) {
+		return web.Principal{}, false, nil
+	}
+
+	principal, err := authenticator.OauthService.Authenticate(request.Context(), token)
+	return principal, true, err
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+// RateLimit is a token bucket refilled with Rate tokens per second up to Burst. A zero Rate disables the limit.
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// RateLimits are applied separately to read (GET, HEAD, OPTIONS) and write requests.
+type RateLimits struct {
+	Read  RateLimit `json:"read"`
+	Write RateLimit `json:"write"`
+}
+
+// DefaultMaxBuckets bounds the memory of a rate limit, one bucket is kept per credential or client IP and route class.
+const DefaultMaxBuckets = 100000
+
+type rateLimitMiddleware struct {
+	defaults   RateLimits
+	keys       map[string]RateLimits
+	maxBuckets int
+
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimitMiddleware limits each credential, keyed like "api_key:12", falling back to the client IP
+// for requests without one. It must run behind the auth middleware to see the credential. Every route
+// wrapped by the returned middleware shares its buckets, at most maxBuckets of them.
+func NewRateLimitMiddleware(defaults RateLimits, keys map[string]RateLimits, maxBuckets int) Middleware {
+	middleware := &rateLimitMiddleware{defaults: defaults, keys: keys, maxBuckets: maxBuckets, buckets: map[string]*tokenBucket{}}
+	return serveWith(middleware.serve)
+}
+
+func (middleware *rateLimitMiddleware) serve(writer http.ResponseWriter, request *http.Request, next http.Handler) {
+	key := rateLimitKey(request)
+	limits, ok := middleware.keys[key]
+	if !ok {
+		limits = middleware.defaults
+	}
+
+	limit, class := limits.Write, routeClass(request)
+	if class == "read" {
+		limit = limits.Read
+	}
+	if limit.Rate <= 0 {
+		next.ServeHTTP(writer, request)
+		return
+	}
+
+	allowed, remaining, retryAfter, reset := middleware.take(key+" "+class, limit, time.Now())
+
+	writer.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+	writer.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	writer.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+
+	if allowed {
+		next.ServeHTTP(writer, request)
+	} else {
+		writer.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+		exeption.WriteError(writer, request, exeption.RateLimited, nil)
+	}
+}
+
+// take spends a token when one is available. reset is how long until the bucket is full again.
+func (middleware *rateLimitMiddleware) take(key string, limit RateLimit, now time.Time) (allowed bool, remaining int, retryAfter time.Duration, reset time.Duration) {
+	middleware.mutex.Lock()
+	defer middleware.mutex.Unlock()
+
+	bucket, ok := middleware.buckets[key]
+	if !ok || bucket.limit != limit {
+		if len(middleware.buckets) >= middleware.maxBuckets {
+			middleware.evict(now)
+		}
+		bucket = &tokenBucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		middleware.buckets[key] = bucket
+	}
+
+	bucket.refill(now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		allowed = true
+	} else {
+		retryAfter = time.Duration((1 - bucket.tokens) / limit.Rate * float64(time.Second))
+	}
+
+	reset = time.Duration((float64(limit.Burst) - bucket.tokens) / limit.Rate * float64(time.Second))
+	return allowed, int(bucket.tokens), retryAfter, reset
+}
+
+// evict makes room for new buckets. The buckets that refilled completely behave exactly like new ones
+// and go first; when every bucket is still in use the least recently used tenth goes too, so maxBuckets
+// stays a real cap even when clients keep coming from new addresses.
+func (middleware *rateLimitMiddleware) evict(now time.Time) {
+	for key, bucket := range middleware.buckets {
+		if bucket.full(now) {
+			delete(middleware.buckets, key)
+		}
+	}
+	if len(middleware.buckets) < middleware.maxBuckets {
+		return
+	}
+
+	keys := make([]string, 0, len(middleware.buckets))
+	for key := range middleware.buckets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return middleware.buckets[keys[i]].last.Before(middleware.buckets[keys[j]].last)
+	})
+	for _, key := range keys[:len(keys)/10+1] {
+		delete(middleware.buckets, key)
+	}
+}
+
+// full reports whether the bucket would be full at now, without refilling it, as last must keep
+// the time of its latest request.
+func (bucket *tokenBucket) full(now time.Time) bool {
+	return bucket.tokens+now.Sub(bucket.last).Seconds()*bucket.limit.Rate >= float64(bucket.limit.Burst)
+}
+
+func (bucket *tokenBucket) refill(now time.Time) {
+	bucket.tokens = math.Min(float64(bucket.limit.Burst), bucket.tokens+now.Sub(bucket.last).Seconds()*bucket.limit.Rate)
+	bucket.last = now
+}
+
+func rateLimitKey(request *http.Request) string {
+	if principal, ok := helper.PrincipalFromContext(request.Context()); ok {
+		return service.CredentialKey(principal)
+	}
+
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		host = request.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// routeClass tells read requests (GET, HEAD, OPTIONS) from write requests.
+func routeClass(request *http.Request) string {
+	if request.Method == http.MethodGet || request.Method == http.MethodHead || request.Method == http.MethodOptions {
+		return "read"
+	}
+	return "write"
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/mrakhaf/golang-restful-api/exeption"
+)
+
+// NewRecoverMiddleware answers panics raised outside the router, like in a middleware, the way the
+// router's PanicHandler answers those of a handle.
+func NewRecoverMiddleware() Middleware {
+	return serveWith(recoverPanic)
+}
+
+func recoverPanic(writer http.ResponseWriter, request *http.Request, next http.Handler) {
+	defer func() {
+		if err := recover(); err != nil {
+			// the server aborts the response on purpose with this one
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			exeption.ErrorHandler(writer, request, err)
+		}
+	}()
+	next.ServeHTTP(writer, request)
+}
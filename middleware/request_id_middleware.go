@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+)
+
+const RequestIdHeader = "X-Request-ID"
+
+// NewRequestIdMiddleware keeps the X-Request-ID a client or proxy sent, or generates one, stores it in the
+// request context and echoes it in the response. It runs in front of every other middleware.
+func NewRequestIdMiddleware() Middleware {
+	return serveWith(serveRequestId)
+}
+
+func serveRequestId(writer http.ResponseWriter, request *http.Request, next http.Handler) {
+	requestId := request.Header.Get(RequestIdHeader)
+	if !validRequestId(requestId) {
+		requestId = helper.NewRequestId()
+	}
+
+	writer.Header().Set(RequestIdHeader, requestId)
+	next.ServeHTTP(writer, request.WithContext(helper.WithRequestId(request.Context(), requestId)))
+}
+
+// validRequestId refuses ids that are too long or could forge log lines.
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > 128 {
+		return false
+	}
+	for _, c := range requestId {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == ':') {
+			return false
+		}
+	}
+	return true
+}
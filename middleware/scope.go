@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+)
+
+// RequireScopes wraps a route so it only runs when the authenticated credential holds every given scope.
+// It panics an exeption.Error with the SCOPE_MISSING code, so the route must be registered on a router using exeption.ErrorHandler.
+func RequireScopes(handle httprouter.Handle, scopes ...string) httprouter.Handle {
+	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		principal, _ := helper.PrincipalFromContext(request.Context())
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				panic(exeption.NewError(exeption.ScopeMissing, "missing scope "+scope))
+			}
+		}
+		handle(writer, request, params)
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/service"
+)
+
+type usageMiddleware struct {
+	usageService service.UsageService
+}
+
+// NewUsageMiddleware counts the requests of every credential and answers 429 once a quota is used up.
+// Like the rate limit it must run behind the auth middleware; requests without a credential are not counted.
+func NewUsageMiddleware(usageService service.UsageService) Middleware {
+	middleware := &usageMiddleware{usageService: usageService}
+	return serveWith(middleware.serve)
+}
+
+func (middleware *usageMiddleware) serve(writer http.ResponseWriter, request *http.Request, next http.Handler) {
+	principal, ok := helper.PrincipalFromContext(request.Context())
+	if !ok {
+		next.ServeHTTP(writer, request)
+		return
+	}
+
+	exceeded, resetAt := middleware.usageService.Count(request.Context(), principal, routeClass(request))
+	if exceeded == "" {
+		next.ServeHTTP(writer, request)
+		return
+	}
+
+	writer.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(time.Until(resetAt))))
+	exeption.WriteError(writer, request, exeption.QuotaExceeded, exceeded+" quota exceeded")
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+type ApiKey struct {
+	Id       int
+	TenantId string
+	Name     string
+	Owner    string
+	Prefix   string
+	KeyHash  string
+	// Scopes are space separated, as in an OAuth2 scope parameter.
+	Scopes     string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	Revoked    bool
+}
@@ -1,6 +1,7 @@
 package domain
 
 type Category struct {
-	Id   int
-	Name string
+	Id       int
+	TenantId string
+	Name     string
 }
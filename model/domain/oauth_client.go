@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+type OauthClient struct {
+	Id         int
+	TenantId   string
+	ClientId   string
+	Name       string
+	SecretHash string
+	// Scopes are space separated, as in an OAuth2 scope parameter.
+	Scopes    string
+	CreatedAt time.Time
+	Revoked   bool
+}
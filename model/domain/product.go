@@ -0,0 +1,9 @@
+package domain
+
+type Product struct {
+	Id         int
+	TenantId   string
+	CategoryId int
+	Name       string
+	Price      int64
+}
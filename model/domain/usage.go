@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Usage counts the requests of one credential and route class on one UTC day.
+type Usage struct {
+	TenantId     string
+	Credential   string
+	RouteClass   string
+	Day          time.Time
+	RequestCount int64
+}
@@ -0,0 +1,10 @@
+package web
+
+import "time"
+
+type ApiKeyCreateRequest struct {
+	Name      string     `validate:"required,max=100,min=1" json:"name"`
+	Owner     string     `validate:"required,max=100,min=1" json:"owner"`
+	Scopes    []string   `validate:"required,min=1,dive,required,max=50,excludesall= *" json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,21 @@
+package web
+
+import "time"
+
+type ApiKeyResponse struct {
+	Id         int        `json:"id"`
+	Name       string     `json:"name"`
+	Owner      string     `json:"owner"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Revoked    bool       `json:"revoked"`
+}
+
+// ApiKeyIssueResponse is the only response that ever contains the plain key.
+type ApiKeyIssueResponse struct {
+	ApiKeyResponse
+	Key string `json:"key"`
+}
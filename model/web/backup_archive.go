@@ -0,0 +1,12 @@
+package web
+
+import "time"
+
+type BackupArchive struct {
+	SchemaVersion int                `json:"schema_version"`
+	Application   string             `json:"application"`
+	Tenant        string             `json:"tenant"`
+	CreatedAt     time.Time          `json:"created_at"`
+	CategoryCount int                `json:"category_count"`
+	Categories    []CategoryResponse `json:"categories"`
+}
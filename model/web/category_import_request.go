@@ -0,0 +1,8 @@
+package web
+
+type CategoryImportRequest struct {
+	DryRun bool
+	Upsert bool
+	// Language is the Accept-Language the validation messages of failed lines follow.
+	Language string
+}
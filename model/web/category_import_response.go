@@ -0,0 +1,20 @@
+package web
+
+type CategoryImportResponse struct {
+	DryRun  bool                       `json:"dry_run"`
+	Upsert  bool                       `json:"upsert"`
+	Total   int                        `json:"total"`
+	Created int                        `json:"created"`
+	Updated int                        `json:"updated"`
+	Failed  int                        `json:"failed"`
+	Lines   []CategoryImportLineResult `json:"lines"`
+}
+
+type CategoryImportLineResult struct {
+	Line   int    `json:"line"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+	// Errors lists the failed validations of the line, Error is then "validation failed".
+	Errors []ValidationError `json:"errors,omitempty"`
+}
@@ -1,6 +1,7 @@
 package web
 
 type CategoryResponse struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	Id           int    `json:"id"`
+	Name         string `json:"name"`
+	ProductCount *int   `json:"product_count,omitempty"`
 }
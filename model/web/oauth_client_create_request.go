@@ -0,0 +1,6 @@
+package web
+
+type OauthClientCreateRequest struct {
+	Name   string   `validate:"required,max=100,min=1" json:"name"`
+	Scopes []string `validate:"required,min=1,dive,required,max=50,excludesall= *" json:"scopes"`
+}
@@ -0,0 +1,18 @@
+package web
+
+import "time"
+
+type OauthClientResponse struct {
+	Id        int       `json:"id"`
+	ClientId  string    `json:"client_id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+	Revoked   bool      `json:"revoked"`
+}
+
+// OauthClientRegisterResponse is the only response that ever contains the plain client secret.
+type OauthClientRegisterResponse struct {
+	OauthClientResponse
+	ClientSecret string `json:"client_secret"`
+}
@@ -0,0 +1,20 @@
+package web
+
+// OauthClientCredentials come from HTTP Basic authentication or the client_id and client_secret form fields.
+type OauthClientCredentials struct {
+	ClientId     string
+	ClientSecret string
+}
+
+// OauthTokenRequest is the form of RFC 6749 section 4.4.2.
+type OauthTokenRequest struct {
+	OauthClientCredentials
+	GrantType string
+	Scope     string
+}
+
+// OauthTokenLookupRequest is the form of the introspection (RFC 7662) and revocation (RFC 7009) endpoints.
+type OauthTokenLookupRequest struct {
+	OauthClientCredentials
+	Token string
+}
@@ -0,0 +1,23 @@
+package web
+
+// OauthTokenResponse is written as is, not wrapped in WebResponse, as OAuth2 clients expect.
+type OauthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// OauthIntrospectResponse only carries active for tokens that are not, per RFC 7662.
+type OauthIntrospectResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Tenant    string `json:"tenant,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	Id        string `json:"jti,omitempty"`
+}
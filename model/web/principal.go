@@ -0,0 +1,22 @@
+package web
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Kind     string
+	Id       string
+	Name     string
+	TenantId string
+	// Scopes granted to the credential, "*" grants every scope.
+	Scopes []string
+	// Claims holds the verified claims when the caller used a bearer token.
+	Claims map[string]interface{}
+}
+
+func (principal Principal) HasScope(scope string) bool {
+	for _, granted := range principal.Scopes {
+		if granted == scope || granted == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package web
+
+import "encoding/json"
+
+// Problem is an RFC 7807 problem details body, sent as application/problem+json.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Extensions are added as members next to the standard ones, like "request_id".
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (problem Problem) MarshalJSON() ([]byte, error) {
+	members := map[string]interface{}{}
+	for name, value := range problem.Extensions {
+		members[name] = value
+	}
+	members["type"] = problem.Type
+	members["title"] = problem.Title
+	members["status"] = problem.Status
+	if problem.Detail != "" {
+		members["detail"] = problem.Detail
+	}
+	if problem.Instance != "" {
+		members["instance"] = problem.Instance
+	}
+	return json.Marshal(members)
+}
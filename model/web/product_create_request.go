@@ -0,0 +1,7 @@
+package web
+
+type ProductCreateRequest struct {
+	CategoryId int    `validate:"required" json:"category_id"`
+	Name       string `validate:"required,max=200,min=1" json:"name"`
+	Price      int64  `validate:"min=0" json:"price"`
+}
@@ -0,0 +1,8 @@
+package web
+
+type ProductResponse struct {
+	Id         int    `json:"id"`
+	CategoryId int    `json:"category_id"`
+	Name       string `json:"name"`
+	Price      int64  `json:"price"`
+}
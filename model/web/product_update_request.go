@@ -0,0 +1,8 @@
+package web
+
+type ProductUpdateRequest struct {
+	Id         int    `validate:"required"`
+	CategoryId int    `validate:"required" json:"category_id"`
+	Name       string `validate:"required,max=200,min=1" json:"name"`
+	Price      int64  `validate:"min=0" json:"price"`
+}
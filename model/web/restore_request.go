@@ -0,0 +1,6 @@
+package web
+
+type RestoreRequest struct {
+	PreserveIds bool
+	Replace     bool
+}
@@ -0,0 +1,8 @@
+package web
+
+type RestoreResponse struct {
+	SchemaVersion int  `json:"schema_version"`
+	PreserveIds   bool `json:"preserve_ids"`
+	Replace       bool `json:"replace"`
+	Restored      int  `json:"restored"`
+}
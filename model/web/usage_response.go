@@ -0,0 +1,21 @@
+package web
+
+type UsageCount struct {
+	Period string `json:"period"`
+	Read   int64  `json:"read"`
+	Write  int64  `json:"write"`
+	Total  int64  `json:"total"`
+}
+
+type UsageQuotaResponse struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+type UsageResponse struct {
+	Credential string             `json:"credential"`
+	Today      UsageCount         `json:"today"`
+	Month      UsageCount         `json:"month"`
+	Days       []UsageCount       `json:"days"`
+	Quota      UsageQuotaResponse `json:"quota"`
+}
@@ -0,0 +1,9 @@
+package web
+
+// ValidationError describes one field that failed validation, like {"field": "name", "rule": "max", "param": "200"}.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
@@ -4,4 +4,8 @@ type WebResponse struct {
 	Code   int         `json:"code"`
 	Status string      `json:"status"`
 	Data   interface{} `json:"data"`
+	// ErrorCode is set on error responses to a code of the exeption.ErrorCodes catalog.
+	ErrorCode string `json:"error_code,omitempty"`
+	// RequestId is set on error responses, so a client can report the request it failed on.
+	RequestId string `json:"request_id,omitempty"`
 }
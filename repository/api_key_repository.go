@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+)
+
+type ApiKeyRepository interface {
+	CrudRepository[domain.ApiKey]
+	// FindByHash is not tenant scoped, it is how the tenant of a request is found in the first place.
+	FindByHash(ctx context.Context, tx *sql.Tx, keyHash string) (domain.ApiKey, error)
+	UpdateLastUsed(ctx context.Context, tx *sql.Tx, apiKeyId int, lastUsedAt time.Time)
+}
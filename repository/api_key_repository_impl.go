@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+)
+
+var apiKeyMapping = TableMapping[domain.ApiKey]{
+	Table:   "api_key",
+	Columns: []string{"name", "owner", "prefix", "key_hash", "scopes", "created_at", "expires_at", "last_used_at", "revoked"},
+	Id:      func(apiKey *domain.ApiKey) *int { return &apiKey.Id },
+	Tenant:  func(apiKey *domain.ApiKey) *string { return &apiKey.TenantId },
+	Fields: func(apiKey *domain.ApiKey) []interface{} {
+		return []interface{}{&apiKey.Name, &apiKey.Owner, &apiKey.Prefix, &apiKey.KeyHash,
+			&apiKey.Scopes, &apiKey.CreatedAt, &apiKey.ExpiresAt, &apiKey.LastUsedAt, &apiKey.Revoked}
+	},
+}
+
+type ApiKeyRepositoryImpl struct {
+	CrudRepositoryImpl[domain.ApiKey]
+}
+
+func NewApiKeyRepository() ApiKeyRepository {
+	return &ApiKeyRepositoryImpl{
+		CrudRepositoryImpl: CrudRepositoryImpl[domain.ApiKey]{Mapping: apiKeyMapping},
+	}
+}
+
+func (repository *ApiKeyRepositoryImpl) FindByHash(ctx context.Context, tx *sql.Tx, keyHash string) (domain.ApiKey, error) {
+	query := "SELECT id, tenant_id, name, owner, prefix, key_hash, scopes, created_at, expires_at, last_used_at, revoked FROM api_key WHERE key_hash = ?"
+	rows, err := tx.QueryContext(ctx, query, keyHash)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	apiKey := domain.ApiKey{}
+	if rows.Next() {
+		err := rows.Scan(apiKeyMapping.scanFields(&apiKey)...)
+		helper.PanicIfError(err)
+		return apiKey, nil
+	} else {
+		return apiKey, errors.New("api key is not found!")
+	}
+}
+
+func (repository *ApiKeyRepositoryImpl) UpdateLastUsed(ctx context.Context, tx *sql.Tx, apiKeyId int, lastUsedAt time.Time) {
+	query := "UPDATE api_key SET last_used_at = ? WHERE id = ?"
+	_, err := tx.ExecContext(ctx, query, lastUsedAt, apiKeyId)
+	helper.PanicIfError(err)
+}
@@ -8,9 +8,8 @@ import (
 )
 
 type CategoryRepository interface {
-	Save(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category
-	Update(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category
-	Delete(ctx context.Context, tx *sql.Tx, category domain.Category)
-	FindById(ctx context.Context, tx *sql.Tx, categoryId int) (domain.Category, error)
-	FindAll(ctx context.Context, tx *sql.Tx) []domain.Category
+	CrudRepository[domain.Category]
+	SaveWithId(ctx context.Context, tx *sql.Tx, category domain.Category) (domain.Category, error)
+	DeleteAll(ctx context.Context, tx *sql.Tx)
+	FindByName(ctx context.Context, tx *sql.Tx, name string) (domain.Category, error)
 }
@@ -9,69 +9,76 @@ import (
 	"github.com/mrakhaf/golang-restful-api/model/domain"
 )
 
-type CategoryRepositoryImpl struct {
-}
-
-func NewCategoryRepository() CategoryRepository {
-	return &CategoryRepositoryImpl{}
+var categoryMapping = TableMapping[domain.Category]{
+	Table:   "category",
+	Columns: []string{"name"},
+	Id:      func(category *domain.Category) *int { return &category.Id },
+	Tenant:  func(category *domain.Category) *string { return &category.TenantId },
+	Fields: func(category *domain.Category) []interface{} {
+		return []interface{}{&category.Name}
+	},
 }
 
-func (repository *CategoryRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category {
-	query := "INSERT INTO category (name) values(?)"
-	result, err := tx.ExecContext(ctx, query, category.Name)
-	helper.PanicIfError(err)
-
-	id, err := result.LastInsertId()
-	helper.PanicIfError(err)
+var (
+	ErrCategoryIdTaken   = errors.New("category id is already used by another tenant")
+	ErrCategoryNameTaken = errors.New("category name is already used by another category")
+)
 
-	category.Id = int(id)
-	return category
+// CategoryRepositoryImpl scopes every statement to the tenant carried by ctx,
+// so rows of other tenants behave as if they did not exist.
+type CategoryRepositoryImpl struct {
+	CrudRepositoryImpl[domain.Category]
 }
 
-func (repository *CategoryRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, category domain.Category) domain.Category {
-	query := "UPDATE category SET name = ? WHERE id = ?"
-	_, err := tx.ExecContext(ctx, query, category.Name, category.Id)
-	helper.PanicIfError(err)
-
-	return category
+func NewCategoryRepository() CategoryRepository {
+	return &CategoryRepositoryImpl{
+		CrudRepositoryImpl: CrudRepositoryImpl[domain.Category]{Mapping: categoryMapping},
+	}
 }
 
-func (repository *CategoryRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, category domain.Category) {
-	query := "DELETE FROM category WHERE id = ?"
-	_, err := tx.ExecContext(ctx, query, category.Id)
-	helper.PanicIfError(err)
-}
+// SaveWithId keeps the id of the given category, overwriting the row that already uses it
+// unless that row belongs to another tenant or another row of the tenant has the name.
+func (repository *CategoryRepositoryImpl) SaveWithId(ctx context.Context, tx *sql.Tx, category domain.Category) (domain.Category, error) {
+	category.TenantId = helper.TenantFromContext(ctx)
 
-func (repository *CategoryRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, categoryId int) (domain.Category, error) {
-	query := "SELECT id, name FROM category WHERE id = ?"
-	rows, err := tx.QueryContext(ctx, query, categoryId)
+	query := "SELECT tenant_id FROM category WHERE id = ? FOR UPDATE"
+	rows, err := tx.QueryContext(ctx, query, category.Id)
 	helper.PanicIfError(err)
-	defer rows.Close()
 
-	category := domain.Category{}
-	if rows.Next() {
-		err := rows.Scan(&category.Id, &category.Name)
+	var owner string
+	exists := rows.Next()
+	if exists {
+		err := rows.Scan(&owner)
 		helper.PanicIfError(err)
-		return category, nil
-	} else {
-		return category, errors.New("category is not found!")
+	}
+	rows.Close()
+
+	if exists && owner != category.TenantId {
+		return category, ErrCategoryIdTaken
+	}
+	// ON DUPLICATE KEY UPDATE would otherwise update the row holding the name instead of the id
+	if len(repository.FindWhere(ctx, tx, "name = ? AND id <> ?", category.Name, category.Id)) > 0 {
+		return category, ErrCategoryNameTaken
 	}
 
+	query = "INSERT INTO category (id, tenant_id, name) values(?, ?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name)"
+	_, err = tx.ExecContext(ctx, query, category.Id, category.TenantId, category.Name)
+	helper.PanicIfError(err)
+
+	return category, nil
 }
 
-func (repository *CategoryRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []domain.Category {
-	query := "SELECT id, name FROM category"
-	rows, err := tx.QueryContext(ctx, query)
+func (repository *CategoryRepositoryImpl) DeleteAll(ctx context.Context, tx *sql.Tx) {
+	// TRUNCATE would commit the surrounding transaction implicitly in MySQL
+	query := "DELETE FROM category WHERE tenant_id = ?"
+	_, err := tx.ExecContext(ctx, query, helper.TenantFromContext(ctx))
 	helper.PanicIfError(err)
-	defer rows.Close()
+}
 
-	var categories []domain.Category
-	for rows.Next() {
-		category := domain.Category{}
-		err := rows.Scan(&category.Id, &category.Name)
-		helper.PanicIfError(err)
-		categories = append(categories, category)
+func (repository *CategoryRepositoryImpl) FindByName(ctx context.Context, tx *sql.Tx, name string) (domain.Category, error) {
+	categories := repository.FindWhere(ctx, tx, "name = ?", name)
+	if len(categories) == 0 {
+		return domain.Category{}, errors.New("category is not found!")
 	}
-	return categories
-
+	return categories[0], nil
 }
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type CrudRepository[T any] interface {
+	Save(ctx context.Context, tx *sql.Tx, entity T) T
+	Update(ctx context.Context, tx *sql.Tx, entity T) T
+	Delete(ctx context.Context, tx *sql.Tx, entity T)
+	FindById(ctx context.Context, tx *sql.Tx, id int) (T, error)
+	FindAll(ctx context.Context, tx *sql.Tx) []T
+	// Each calls fn with every row while reading them, for results too large to hold in memory.
+	Each(ctx context.Context, tx *sql.Tx, fn func(entity T))
+}
+
+// TableMapping declares how a tenant-scoped table maps onto the domain type T.
+// The table must have an auto increment id column and a tenant_id column.
+type TableMapping[T any] struct {
+	Table   string
+	Columns []string
+	Id      func(entity *T) *int
+	Tenant  func(entity *T) *string
+	// Fields returns pointers to every entry of Columns, in that order. They are used both as scan
+	// destinations and as statement arguments.
+	Fields func(entity *T) []interface{}
+}
+
+// scanFields returns the scan destinations of a row selected as id, tenant_id and then Columns.
+func (mapping TableMapping[T]) scanFields(entity *T) []interface{} {
+	return append([]interface{}{mapping.Id(entity), mapping.Tenant(entity)}, mapping.Fields(entity)...)
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+)
+
+// CrudRepositoryImpl implements CrudRepository from a TableMapping, scoping every statement
+// to the tenant carried by ctx. Resources embed it and add their own finders next to it.
+type CrudRepositoryImpl[T any] struct {
+	Mapping TableMapping[T]
+}
+
+func NewCrudRepository[T any](mapping TableMapping[T]) CrudRepository[T] {
+	return &CrudRepositoryImpl[T]{Mapping: mapping}
+}
+
+func (repository *CrudRepositoryImpl[T]) Save(ctx context.Context, tx *sql.Tx, entity T) T {
+	tenantId := repository.Mapping.Tenant(&entity)
+	*tenantId = helper.TenantFromContext(ctx)
+
+	columns := append([]string{"tenant_id"}, repository.Mapping.Columns...)
+	query := "INSERT INTO " + repository.Mapping.Table + " (" + strings.Join(columns, ", ") + ") values(" + placeholders(len(columns)) + ")"
+	// database/sql dereferences pointer arguments, so the fields can be passed as they are
+	args := append([]interface{}{tenantId}, repository.Mapping.Fields(&entity)...)
+	result, err := tx.ExecContext(ctx, query, args...)
+	helper.PanicIfError(err)
+
+	id, err := result.LastInsertId()
+	helper.PanicIfError(err)
+
+	*repository.Mapping.Id(&entity) = int(id)
+	return entity
+}
+
+func (repository *CrudRepositoryImpl[T]) Update(ctx context.Context, tx *sql.Tx, entity T) T {
+	tenantId := repository.Mapping.Tenant(&entity)
+	*tenantId = helper.TenantFromContext(ctx)
+
+	assignments := make([]string, len(repository.Mapping.Columns))
+	for i, column := range repository.Mapping.Columns {
+		assignments[i] = column + " = ?"
+	}
+
+	query := "UPDATE " + repository.Mapping.Table + " SET " + strings.Join(assignments, ", ") + " WHERE id = ? AND tenant_id = ?"
+	args := append(repository.Mapping.Fields(&entity), repository.Mapping.Id(&entity), tenantId)
+	_, err := tx.ExecContext(ctx, query, args...)
+	helper.PanicIfError(err)
+
+	return entity
+}
+
+func (repository *CrudRepositoryImpl[T]) Delete(ctx context.Context, tx *sql.Tx, entity T) {
+	query := "DELETE FROM " + repository.Mapping.Table + " WHERE id = ? AND tenant_id = ?"
+	_, err := tx.ExecContext(ctx, query, *repository.Mapping.Id(&entity), helper.TenantFromContext(ctx))
+	helper.PanicIfError(err)
+}
+
+func (repository *CrudRepositoryImpl[T]) FindById(ctx context.Context, tx *sql.Tx, id int) (T, error) {
+	entities := repository.FindWhere(ctx, tx, "id = ?", id)
+	if len(entities) == 0 {
+		var entity T
+		return entity, errors.New(repository.Mapping.Table + " is not found!")
+	}
+	return entities[0], nil
+}
+
+func (repository *CrudRepositoryImpl[T]) FindAll(ctx context.Context, tx *sql.Tx) []T {
+	return repository.FindWhere(ctx, tx, "")
+}
+
+func (repository *CrudRepositoryImpl[T]) Each(ctx context.Context, tx *sql.Tx, fn func(entity T)) {
+	repository.eachWhere(ctx, tx, fn, "")
+}
+
+// FindWhere returns the rows of the current tenant matching condition, or all of them when condition is empty.
+func (repository *CrudRepositoryImpl[T]) FindWhere(ctx context.Context, tx *sql.Tx, condition string, args ...interface{}) []T {
+	var entities []T
+	repository.eachWhere(ctx, tx, func(entity T) {
+		entities = append(entities, entity)
+	}, condition, args...)
+	return entities
+}
+
+func (repository *CrudRepositoryImpl[T]) eachWhere(ctx context.Context, tx *sql.Tx, fn func(entity T), condition string, args ...interface{}) {
+	columns := append([]string{"id", "tenant_id"}, repository.Mapping.Columns...)
+	query := "SELECT " + strings.Join(columns, ", ") + " FROM " + repository.Mapping.Table + " WHERE tenant_id = ?"
+	if condition != "" {
+		query += " AND " + condition
+	}
+
+	rows, err := tx.QueryContext(ctx, query, append([]interface{}{helper.TenantFromContext(ctx)}, args...)...)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	for rows.Next() {
+		var entity T
+		err := rows.Scan(repository.Mapping.scanFields(&entity)...)
+		helper.PanicIfError(err)
+		fn(entity)
+	}
+	helper.PanicIfError(rows.Err())
+}
+
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
+}
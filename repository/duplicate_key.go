@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDuplicateEntry is the error number of a row rejected by a unique index.
+const mysqlDuplicateEntry = 1062
+
+// IsDuplicateKey reports whether err, a recovered panic value or an error, comes from a unique index.
+func IsDuplicateKey(err interface{}) bool {
+	cause, ok := err.(error)
+	if !ok {
+		return false
+	}
+	var mysqlError *mysql.MySQLError
+	return errors.As(cause, &mysqlError) && mysqlError.Number == mysqlDuplicateEntry
+}
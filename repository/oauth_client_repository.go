@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+)
+
+type OauthClientRepository interface {
+	CrudRepository[domain.OauthClient]
+	// FindByClientId is not tenant scoped, the client decides the tenant of the tokens it gets.
+	FindByClientId(ctx context.Context, tx *sql.Tx, clientId string) (domain.OauthClient, error)
+}
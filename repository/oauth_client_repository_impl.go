@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+)
+
+var oauthClientMapping = TableMapping[domain.OauthClient]{
+	Table:   "oauth_client",
+	Columns: []string{"client_id", "name", "secret_hash", "scopes", "created_at", "revoked"},
+	Id:      func(client *domain.OauthClient) *int { return &client.Id },
+	Tenant:  func(client *domain.OauthClient) *string { return &client.TenantId },
+	Fields: func(client *domain.OauthClient) []interface{} {
+		return []interface{}{&client.ClientId, &client.Name, &client.SecretHash, &client.Scopes, &client.CreatedAt, &client.Revoked}
+	},
+}
+
+type OauthClientRepositoryImpl struct {
+	CrudRepositoryImpl[domain.OauthClient]
+}
+
+func NewOauthClientRepository() OauthClientRepository {
+	return &OauthClientRepositoryImpl{
+		CrudRepositoryImpl: CrudRepositoryImpl[domain.OauthClient]{Mapping: oauthClientMapping},
+	}
+}
+
+func (repository *OauthClientRepositoryImpl) FindByClientId(ctx context.Context, tx *sql.Tx, clientId string) (domain.OauthClient, error) {
+	query := "SELECT id, tenant_id, client_id, name, secret_hash, scopes, created_at, revoked FROM oauth_client WHERE client_id = ?"
+	rows, err := tx.QueryContext(ctx, query, clientId)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	client := domain.OauthClient{}
+	if rows.Next() {
+		err := rows.Scan(oauthClientMapping.scanFields(&client)...)
+		helper.PanicIfError(err)
+		return client, nil
+	} else {
+		return client, errors.New("oauth client is not found!")
+	}
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// OauthTokenRepository keeps the ids of revoked access tokens until they would have expired anyway.
+type OauthTokenRepository interface {
+	Revoke(ctx context.Context, tx *sql.Tx, jti string, clientId string, expiresAt time.Time)
+	IsRevoked(ctx context.Context, tx *sql.Tx, jti string) bool
+	DeleteExpired(ctx context.Context, tx *sql.Tx, now time.Time)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+)
+
+type OauthTokenRepositoryImpl struct {
+}
+
+func NewOauthTokenRepository() OauthTokenRepository {
+	return &OauthTokenRepositoryImpl{}
+}
+
+func (repository *OauthTokenRepositoryImpl) Revoke(ctx context.Context, tx *sql.Tx, jti string, clientId string, expiresAt time.Time) {
+	query := "INSERT IGNORE INTO oauth_revoked_token (jti, client_id, expires_at) values(?, ?, ?)"
+	_, err := tx.ExecContext(ctx, query, jti, clientId, expiresAt)
+	helper.PanicIfError(err)
+}
+
+func (repository *OauthTokenRepositoryImpl) IsRevoked(ctx context.Context, tx *sql.Tx, jti string) bool {
+	query := "SELECT 1 FROM oauth_revoked_token WHERE jti = ?"
+	rows, err := tx.QueryContext(ctx, query, jti)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	return rows.Next()
+}
+
+func (repository *OauthTokenRepositoryImpl) DeleteExpired(ctx context.Context, tx *sql.Tx, now time.Time) {
+	query := "DELETE FROM oauth_revoked_token WHERE expires_at < ?"
+	_, err := tx.ExecContext(ctx, query, now)
+	helper.PanicIfError(err)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+)
+
+type ProductRepository interface {
+	Save(ctx context.Context, tx *sql.Tx, product domain.Product) domain.Product
+	Update(ctx context.Context, tx *sql.Tx, product domain.Product) domain.Product
+	Delete(ctx context.Context, tx *sql.Tx, product domain.Product)
+	DeleteByCategoryId(ctx context.Context, tx *sql.Tx, categoryId int)
+	FindById(ctx context.Context, tx *sql.Tx, productId int) (domain.Product, error)
+	FindAll(ctx context.Context, tx *sql.Tx) []domain.Product
+	FindByCategoryId(ctx context.Context, tx *sql.Tx, categoryId int) []domain.Product
+	CountByCategoryIds(ctx context.Context, tx *sql.Tx, categoryIds []int) map[int]int
+	Count(ctx context.Context, tx *sql.Tx) int
+}
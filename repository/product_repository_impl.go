@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+)
+
+// ProductRepositoryImpl scopes every statement to the tenant carried by ctx, like CategoryRepositoryImpl.
+type ProductRepositoryImpl struct {
+}
+
+func NewProductRepository() ProductRepository {
+	return &ProductRepositoryImpl{}
+}
+
+func (repository *ProductRepositoryImpl) Save(ctx context.Context, tx *sql.Tx, product domain.Product) domain.Product {
+	product.TenantId = helper.TenantFromContext(ctx)
+
+	query := "INSERT INTO product (tenant_id, category_id, name, price) values(?, ?, ?, ?)"
+	result, err := tx.ExecContext(ctx, query, product.TenantId, product.CategoryId, product.Name, product.Price)
+	helper.PanicIfError(err)
+
+	id, err := result.LastInsertId()
+	helper.PanicIfError(err)
+
+	product.Id = int(id)
+	return product
+}
+
+func (repository *ProductRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, product domain.Product) domain.Product {
+	product.TenantId = helper.TenantFromContext(ctx)
+
+	query := "UPDATE product SET category_id = ?, name = ?, price = ? WHERE id = ? AND tenant_id = ?"
+	_, err := tx.ExecContext(ctx, query, product.CategoryId, product.Name, product.Price, product.Id, product.TenantId)
+	helper.PanicIfError(err)
+
+	return product
+}
+
+func (repository *ProductRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, product domain.Product) {
+	query := "DELETE FROM product WHERE id = ? AND tenant_id = ?"
+	_, err := tx.ExecContext(ctx, query, product.Id, helper.TenantFromContext(ctx))
+	helper.PanicIfError(err)
+}
+
+func (repository *ProductRepositoryImpl) DeleteByCategoryId(ctx context.Context, tx *sql.Tx, categoryId int) {
+	query := "DELETE FROM product WHERE category_id = ? AND tenant_id = ?"
+	_, err := tx.ExecContext(ctx, query, categoryId, helper.TenantFromContext(ctx))
+	helper.PanicIfError(err)
+}
+
+func (repository *ProductRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, productId int) (domain.Product, error) {
+	query := "SELECT id, tenant_id, category_id, name, price FROM product WHERE id = ? AND tenant_id = ?"
+	rows, err := tx.QueryContext(ctx, query, productId, helper.TenantFromContext(ctx))
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	product := domain.Product{}
+	if rows.Next() {
+		err := rows.Scan(&product.Id, &product.TenantId, &product.CategoryId, &product.Name, &product.Price)
+		helper.PanicIfError(err)
+		return product, nil
+	} else {
+		return product, errors.New("product is not found!")
+	}
+}
+
+func (repository *ProductRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []domain.Product {
+	query := "SELECT id, tenant_id, category_id, name, price FROM product WHERE tenant_id = ?"
+	return repository.findProducts(ctx, tx, query, helper.TenantFromContext(ctx))
+}
+
+func (repository *ProductRepositoryImpl) FindByCategoryId(ctx context.Context, tx *sql.Tx, categoryId int) []domain.Product {
+	query := "SELECT id, tenant_id, category_id, name, price FROM product WHERE category_id = ? AND tenant_id = ?"
+	return repository.findProducts(ctx, tx, query, categoryId, helper.TenantFromContext(ctx))
+}
+
+func (repository *ProductRepositoryImpl) CountByCategoryIds(ctx context.Context, tx *sql.Tx, categoryIds []int) map[int]int {
+	counts := map[int]int{}
+	if len(categoryIds) == 0 {
+		return counts
+	}
+
+	args := []interface{}{helper.TenantFromContext(ctx)}
+	for _, categoryId := range categoryIds {
+		args = append(args, categoryId)
+	}
+
+	query := "SELECT category_id, COUNT(*) FROM product WHERE tenant_id = ? AND category_id IN (" + placeholders(len(categoryIds)) + ") GROUP BY category_id"
+	rows, err := tx.QueryContext(ctx, query, args...)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	for rows.Next() {
+		var categoryId, count int
+		err := rows.Scan(&categoryId, &count)
+		helper.PanicIfError(err)
+		counts[categoryId] = count
+	}
+	return counts
+}
+
+func (repository *ProductRepositoryImpl) Count(ctx context.Context, tx *sql.Tx) int {
+	query := "SELECT COUNT(*) FROM product WHERE tenant_id = ?"
+	var count int
+	err := tx.QueryRowContext(ctx, query, helper.TenantFromContext(ctx)).Scan(&count)
+	helper.PanicIfError(err)
+	return count
+}
+
+func (repository *ProductRepositoryImpl) findProducts(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) []domain.Product {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	var products []domain.Product
+	for rows.Next() {
+		product := domain.Product{}
+		err := rows.Scan(&product.Id, &product.TenantId, &product.CategoryId, &product.Name, &product.Price)
+		helper.PanicIfError(err)
+		products = append(products, product)
+	}
+	return products
+}
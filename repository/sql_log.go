@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+)
+
+// DefaultRedactColumns are matched against the column a bound argument is compared with or inserted into.
+var DefaultRedactColumns = []string{"hash", "secret", "password", "token"}
+
+type SqlLogConfig struct {
+	// Logger defaults to slog.Default. Every statement is logged at debug level.
+	Logger *slog.Logger
+	// SlowThreshold logs statements taking at least this long at warn level, zero disables it.
+	SlowThreshold time.Duration
+	// LogArgs adds the bound arguments, those of columns containing one of RedactColumns are redacted
+	// and those of no known column show only their type.
+	LogArgs       bool
+	RedactColumns []string
+}
+
+// NewLoggingConnector wraps the connections of connector, so the *sql.DB opened on it and every
+// *sql.Tx begun from it log their statements with the request id of the context they ran in.
+func NewLoggingConnector(connector driver.Connector, config SqlLogConfig) driver.Connector {
+	return &loggingConnector{Connector: connector, config: &config}
+}
+
+type loggingConnector struct {
+	driver.Connector
+	config *SqlLogConfig
+}
+
+func (connector *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := connector.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingConn{Conn: conn, config: connector.config}, nil
+}
+
+// loggingConn passes the optional driver interfaces through, it assumes the wrapped driver
+// implements the context aware ones like the MySQL driver does.
+type loggingConn struct {
+	driver.Conn
+	config *SqlLogConfig
+}
+
+func (conn *loggingConn) Prepare(query string) (driver.Stmt, error) {
+	return conn.PrepareContext(context.Background(), query)
+}
+
+func (conn *loggingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := conn.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = conn.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &loggingStmt{Stmt: stmt, query: query, config: conn.config}, nil
+}
+
+func (conn *loggingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := conn.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return conn.Conn.Begin()
+}
+
+func (conn *loggingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := conn.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		conn.config.log(ctx, query, args, time.Since(start), rowsAffected(result), err)
+	}
+	return result, err
+}
+
+func (conn *loggingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := conn.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		if !errors.Is(err, driver.ErrSkip) {
+			conn.config.log(ctx, query, args, time.Since(start), 0, err)
+		}
+		return nil, err
+	}
+	return &loggingRows{Rows: rows, ctx: ctx, query: query, args: args, start: start, config: conn.config}, nil
+}
+
+func (conn *loggingConn) Ping(ctx context.Context) error {
+	if pinger, ok := conn.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (conn *loggingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := conn.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (conn *loggingConn) IsValid() bool {
+	if validator, ok := conn.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (conn *loggingConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := conn.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+type loggingStmt struct {
+	driver.Stmt
+	query  string
+	config *SqlLogConfig
+}
+
+func (stmt *loggingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if execer, ok := stmt.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = stmt.Stmt.Exec(namedValues(args))
+	}
+	stmt.config.log(ctx, stmt.query, args, time.Since(start), rowsAffected(result), err)
+	return result, err
+}
+
+func (stmt *loggingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := stmt.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = stmt.Stmt.Query(namedValues(args))
+	}
+	if err != nil {
+		stmt.config.log(ctx, stmt.query, args, time.Since(start), 0, err)
+		return nil, err
+	}
+	return &loggingRows{Rows: rows, ctx: ctx, query: stmt.query, args: args, start: start, config: stmt.config}, nil
+}
+
+func (stmt *loggingStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := stmt.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// loggingRows logs the query once its rows are closed, with the time spent reading them.
+type loggingRows struct {
+	driver.Rows
+	ctx    context.Context
+	query  string
+	args   []driver.NamedValue
+	start  time.Time
+	count  int64
+	err    error
+	config *SqlLogConfig
+	closed bool
+}
+
+func (rows *loggingRows) Next(dest []driver.Value) error {
+	err := rows.Rows.Next(dest)
+	if err == nil {
+		rows.count++
+	} else if err != io.EOF {
+		rows.err = err
+	}
+	return err
+}
+
+func (rows *loggingRows) Close() error {
+	err := rows.Rows.Close()
+	if !rows.closed {
+		rows.closed = true
+		rows.config.log(rows.ctx, rows.query, rows.args, time.Since(rows.start), rows.count, rows.err)
+	}
+	return err
+}
+
+func (config *SqlLogConfig) log(ctx context.Context, query string, args []driver.NamedValue, duration time.Duration, rows int64, err error) {
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	level, message := slog.LevelDebug, "sql"
+	if err != nil {
+		level, message = slog.LevelError, "sql failed"
+	} else if config.SlowThreshold > 0 && duration >= config.SlowThreshold {
+		level, message = slog.LevelWarn, "slow sql"
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("statement", strings.Join(strings.Fields(query), " ")),
+		slog.Duration("duration", duration),
+		slog.Int64("rows", rows),
+		slog.String("request_id", helper.RequestIdFromContext(ctx)),
+	}
+	if config.LogArgs {
+		attrs = append(attrs, slog.Any("args", config.redactArgs(query, args)))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, level, message, attrs...)
+}
+
+// redactArgs fails closed: an argument whose column can't be placed, like those of IN (?, ?) or of an
+// INSERT without a column list, is logged as its type only.
+func (config *SqlLogConfig) redactArgs(query string, args []driver.NamedValue) []string {
+	columns := placeholderColumns(query)
+	values := make([]string, len(args))
+	for i, arg := range args {
+		column := ""
+		if i < len(columns) {
+			column = strings.ToLower(columns[i])
+		}
+		values[i] = fmt.Sprint(arg.Value)
+		if column == "" {
+			values[i] = fmt.Sprintf("[%T]", arg.Value)
+		}
+		if content, ok := arg.Value.([]byte); ok {
+			values[i] = fmt.Sprintf("[%d bytes]", len(content))
+		}
+		for _, redacted := range config.RedactColumns {
+			if strings.Contains(column, strings.ToLower(redacted)) {
+				values[i] = "[REDACTED]"
+			}
+		}
+	}
+	return values
+}
+
+var (
+	insertPattern     = regexp.MustCompile(`(?is)^\s*insert\s+(?:ignore\s+)?into\s+\S+\s*\(([^)]*)\)\s*values`)
+	comparisonPattern = regexp.MustCompile(`(?i)([a-z_][a-z0-9_.]*)\s*(?:=|<>|!=|<=|>=|<|>|\s+like)\s*\?`)
+)
+
+// placeholderColumns guesses the column of every ? in query: the inserted column of a placeholder
+// standing in a VALUES row, or the column a placeholder is compared with. Placeholders it can't
+// place are "".
+func placeholderColumns(query string) []string {
+	columns := make([]string, strings.Count(query, "?"))
+	if match := insertPattern.FindStringSubmatchIndex(query); match != nil {
+		inserted := strings.Split(query[match[2]:match[3]], ",")
+		index, item, depth := strings.Count(query[:match[1]], "?"), 0, 0
+	rows:
+		for _, c := range query[match[1]:] {
+			switch {
+			case c == '(':
+				depth++
+				if depth == 1 {
+					item = 0
+				}
+			case c == ')':
+				depth--
+			case c == ',' && depth == 1:
+				item++
+			case c == '?':
+				// a placeholder nested in a function call may be anything, like a part of the value
+				if depth == 1 && item < len(inserted) {
+					columns[index] = strings.TrimSpace(inserted[item])
+				}
+				index++
+			case depth == 0 && c != ',' && c != ' ' && c != '\t' && c != '\n' && c != '\r':
+				// the rows ended, like at ON DUPLICATE KEY UPDATE
+				break rows
+			}
+		}
+	}
+
+	for _, match := range comparisonPattern.FindAllStringSubmatchIndex(query, -1) {
+		// the placeholder closes the match, its index is the number of ? before it
+		index := strings.Count(query[:match[1]-1], "?")
+		column := query[match[2]:match[3]]
+		if dot := strings.LastIndex(column, "."); dot >= 0 {
+			column = column[dot+1:]
+		}
+		columns[index] = column
+	}
+	return columns
+}
+
+func rowsAffected(result driver.Result) int64 {
+	if result == nil {
+		return 0
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return rows
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
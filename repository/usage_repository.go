@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+)
+
+type UsageRepository interface {
+	// Increment adds usage.RequestCount to the stored counter; it uses usage.TenantId as the counters
+	// of every tenant are flushed together.
+	Increment(ctx context.Context, tx *sql.Tx, usage domain.Usage)
+	// FindByCredential returns the tenant scoped counters of credential from the day since on.
+	FindByCredential(ctx context.Context, tx *sql.Tx, credential string, since time.Time) []domain.Usage
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+)
+
+type UsageRepositoryImpl struct {
+}
+
+func NewUsageRepository() UsageRepository {
+	return &UsageRepositoryImpl{}
+}
+
+func (repository *UsageRepositoryImpl) Increment(ctx context.Context, tx *sql.Tx, usage domain.Usage) {
+	query := "INSERT INTO usage_counter (tenant_id, credential, route_class, day, request_count) values(?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE request_count = request_count + VALUES(request_count)"
+	_, err := tx.ExecContext(ctx, query, usage.TenantId, usage.Credential, usage.RouteClass, usage.Day.Format("2006-01-02"), usage.RequestCount)
+	helper.PanicIfError(err)
+}
+
+func (repository *UsageRepositoryImpl) FindByCredential(ctx context.Context, tx *sql.Tx, credential string, since time.Time) []domain.Usage {
+	query := "SELECT tenant_id, credential, route_class, day, request_count FROM usage_counter WHERE tenant_id = ? AND credential = ? AND day >= ? ORDER BY day, route_class"
+	rows, err := tx.QueryContext(ctx, query, helper.TenantFromContext(ctx), credential, since.Format("2006-01-02"))
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	var usages []domain.Usage
+	for rows.Next() {
+		usage := domain.Usage{}
+		err := rows.Scan(&usage.TenantId, &usage.Credential, &usage.RouteClass, &usage.Day, &usage.RequestCount)
+		helper.PanicIfError(err)
+		usages = append(usages, usage)
+	}
+	return usages
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+type ApiKeyService interface {
+	Issue(ctx context.Context, request web.ApiKeyCreateRequest) web.ApiKeyIssueResponse
+	FindAll(ctx context.Context) []web.ApiKeyResponse
+	Rotate(ctx context.Context, apiKeyId int) web.ApiKeyIssueResponse
+	Revoke(ctx context.Context, apiKeyId int)
+	Authenticate(ctx context.Context, key string) (web.Principal, error)
+}
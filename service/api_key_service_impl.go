@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/repository"
+)
+
+const apiKeyPrefixLength = 11
+
+type ApiKeyServiceImpl struct {
+	ApiKeyRepository repository.ApiKeyRepository
+	DB               *sql.DB
+	Validate         *validator.Validate
+	// Cache, which may be nil, forgets revoked and rotated keys.
+	Cache ApiKeyEvicter
+}
+
+// ApiKeyEvicter forgets the cached lookups of a key hash, see middleware.ApiKeyCache.
+type ApiKeyEvicter interface {
+	Delete(keyHash string)
+}
+
+// Constructor for ApiKeyServiceImpl
+func NewApiKeyService(ApiKeyRepository repository.ApiKeyRepository, DB *sql.DB, Validate *validator.Validate, Cache ApiKeyEvicter) ApiKeyService {
+	return &ApiKeyServiceImpl{ApiKeyRepository: ApiKeyRepository, DB: DB, Validate: Validate, Cache: Cache}
+}
+
+// HashApiKey is what gets stored and looked up; keys are random enough that a plain SHA-256 is sufficient.
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (service *ApiKeyServiceImpl) Issue(ctx context.Context, request web.ApiKeyCreateRequest) web.ApiKeyIssueResponse {
+	//validate
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		panic(exeption.NewError(exeption.ApiKeyExpiryInPast, ""))
+	}
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	return service.issue(ctx, tx, request.Name, request.Owner, strings.Join(request.Scopes, " "), request.ExpiresAt)
+}
+
+func (service *ApiKeyServiceImpl) FindAll(ctx context.Context) []web.ApiKeyResponse {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	apiKeys := service.ApiKeyRepository.FindAll(ctx, tx)
+
+	return helper.ToResponses(apiKeys, helper.ToApiKeyResponse)
+}
+
+// Rotate revokes the key and issues a replacement with the same name, owner, scopes and expiry.
+func (service *ApiKeyServiceImpl) Rotate(ctx context.Context, apiKeyId int) web.ApiKeyIssueResponse {
+	response, keyHash := service.rotate(ctx, apiKeyId)
+	service.evict(keyHash)
+	return response
+}
+
+func (service *ApiKeyServiceImpl) rotate(ctx context.Context, apiKeyId int) (web.ApiKeyIssueResponse, string) {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	apiKey, err := service.ApiKeyRepository.FindById(ctx, tx, apiKeyId)
+	if err != nil {
+		panic(exeption.NewError(exeption.ApiKeyNotFound, err.Error()))
+	}
+	if apiKey.Revoked {
+		panic(exeption.NewError(exeption.ApiKeyRevoked, ""))
+	}
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		panic(exeption.NewError(exeption.ApiKeyExpired, ""))
+	}
+
+	apiKey.Revoked = true
+	service.ApiKeyRepository.Update(ctx, tx, apiKey)
+
+	return service.issue(ctx, tx, apiKey.Name, apiKey.Owner, apiKey.Scopes, apiKey.ExpiresAt), apiKey.KeyHash
+}
+
+func (service *ApiKeyServiceImpl) Revoke(ctx context.Context, apiKeyId int) {
+	service.evict(service.revoke(ctx, apiKeyId))
+}
+
+func (service *ApiKeyServiceImpl) revoke(ctx context.Context, apiKeyId int) string {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	apiKey, err := service.ApiKeyRepository.FindById(ctx, tx, apiKeyId)
+	if err != nil {
+		panic(exeption.NewError(exeption.ApiKeyNotFound, err.Error()))
+	}
+
+	apiKey.Revoked = true
+	service.ApiKeyRepository.Update(ctx, tx, apiKey)
+	return apiKey.KeyHash
+}
+
+// evict runs once the revocation is committed, so a concurrent lookup cannot cache the key again.
+func (service *ApiKeyServiceImpl) evict(keyHash string) {
+	if service.Cache != nil {
+		service.Cache.Delete(keyHash)
+	}
+}
+
+func (service *ApiKeyServiceImpl) Authenticate(ctx context.Context, key string) (web.Principal, error) {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	apiKey, err := service.ApiKeyRepository.FindByHash(ctx, tx, HashApiKey(key))
+	if err != nil {
+		return web.Principal{}, err
+	}
+	if apiKey.Revoked {
+		return web.Principal{}, errors.New("api key is revoked")
+	}
+
+	now := time.Now().UTC()
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now) {
+		return web.Principal{}, errors.New("api key is expired")
+	}
+
+	service.ApiKeyRepository.UpdateLastUsed(ctx, tx, apiKey.Id, now.Truncate(time.Second))
+
+	return web.Principal{
+		Kind:     "api_key",
+		Id:       strconv.Itoa(apiKey.Id),
+		Name:     apiKey.Name,
+		TenantId: apiKey.TenantId,
+		Scopes:   strings.Fields(apiKey.Scopes),
+	}, nil
+}
+
+func (service *ApiKeyServiceImpl) issue(ctx context.Context, tx *sql.Tx, name string, owner string, scopes string, expiresAt *time.Time) web.ApiKeyIssueResponse {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	helper.PanicIfError(err)
+	key := "rk_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := service.ApiKeyRepository.Save(ctx, tx, domain.ApiKey{
+		Name:      name,
+		Owner:     owner,
+		Prefix:    key[:apiKeyPrefixLength],
+		KeyHash:   HashApiKey(key),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		ExpiresAt: expiresAt,
+	})
+
+	return web.ApiKeyIssueResponse{
+		ApiKeyResponse: helper.ToApiKeyResponse(apiKey),
+		Key:            key,
+	}
+}
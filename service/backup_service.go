@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+type BackupService interface {
+	Backup(ctx context.Context) web.BackupArchive
+	Restore(ctx context.Context, archive web.BackupArchive, request web.RestoreRequest) web.RestoreResponse
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/repository"
+)
+
+// BackupSchemaVersion is bumped whenever the archive layout changes in a way older restores cannot read.
+const BackupSchemaVersion = 1
+
+const backupApplication = "golang-restful-api"
+
+type BackupServiceImpl struct {
+	CategoryRepository repository.CategoryRepository
+	ProductRepository  repository.ProductRepository
+	DB                 *sql.DB
+	Validate           *validator.Validate
+}
+
+// Constructor for BackupServiceImpl
+func NewBackupService(CategoryRepository repository.CategoryRepository, ProductRepository repository.ProductRepository, DB *sql.DB, Validate *validator.Validate) BackupService {
+	return &BackupServiceImpl{CategoryRepository: CategoryRepository, ProductRepository: ProductRepository, DB: DB, Validate: Validate}
+}
+
+func (service *BackupServiceImpl) Backup(ctx context.Context) web.BackupArchive {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	categories := helper.ToResponses(service.CategoryRepository.FindAll(ctx, tx), helper.ToCategoryResponse)
+	if categories == nil {
+		categories = []web.CategoryResponse{}
+	}
+
+	return web.BackupArchive{
+		SchemaVersion: BackupSchemaVersion,
+		Application:   backupApplication,
+		Tenant:        helper.TenantFromContext(ctx),
+		CreatedAt:     time.Now().UTC(),
+		CategoryCount: len(categories),
+		Categories:    categories,
+	}
+}
+
+func (service *BackupServiceImpl) Restore(ctx context.Context, archive web.BackupArchive, request web.RestoreRequest) web.RestoreResponse {
+	if archive.SchemaVersion < 1 || archive.SchemaVersion > BackupSchemaVersion {
+		panic(exeption.NewError(exeption.BackupVersionUnsupported, fmt.Sprintf("unsupported backup schema version %d", archive.SchemaVersion)))
+	}
+	if archive.CategoryCount != len(archive.Categories) {
+		panic(exeption.NewError(exeption.BackupInvalid, fmt.Sprintf("backup declares %d categories but contains %d", archive.CategoryCount, len(archive.Categories))))
+	}
+
+	for _, category := range archive.Categories {
+		err := service.Validate.Struct(web.CategoryCreateRequest{Name: category.Name})
+		helper.PanicIfError(err)
+		if request.PreserveIds && category.Id <= 0 {
+			panic(exeption.NewError(exeption.BackupInvalid, fmt.Sprintf("category %q has no id to preserve", category.Name)))
+		}
+	}
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+	// an archive may hold a name twice, or one already used when it is restored without replacing
+	defer conflictOnDuplicateKey(exeption.CategoryNameTaken)
+
+	if request.Replace {
+		// the archive holds categories only, so replacing them must not orphan products
+		if productCount := service.ProductRepository.Count(ctx, tx); productCount > 0 {
+			panic(exeption.NewError(exeption.BackupReplaceBlocked, fmt.Sprintf("cannot replace categories while %d products reference them", productCount)))
+		}
+		service.CategoryRepository.DeleteAll(ctx, tx)
+	}
+
+	for _, category := range archive.Categories {
+		if request.PreserveIds {
+			_, err := service.CategoryRepository.SaveWithId(ctx, tx, domain.Category{Id: category.Id, Name: category.Name})
+			if errors.Is(err, repository.ErrCategoryNameTaken) {
+				panic(exeption.NewError(exeption.CategoryNameTaken, fmt.Sprintf("cannot restore category %d: %s", category.Id, err.Error())))
+			}
+			if err != nil {
+				panic(exeption.NewError(exeption.CategoryIdTaken, fmt.Sprintf("cannot restore category %d: %s", category.Id, err.Error())))
+			}
+		} else {
+			service.CategoryRepository.Save(ctx, tx, domain.Category{Name: category.Name})
+		}
+	}
+
+	return web.RestoreResponse{
+		SchemaVersion: archive.SchemaVersion,
+		PreserveIds:   request.PreserveIds,
+		Replace:       request.Replace,
+		Restored:      len(archive.Categories),
+	}
+}
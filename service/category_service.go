@@ -2,14 +2,15 @@ package service
 
 import (
 	"context"
+	"io"
 
 	"github.com/mrakhaf/golang-restful-api/model/web"
 )
 
 type CategoryService interface {
-	Create(ctx context.Context, request web.CategoryCreateRequest) web.CategoryResponse
-	Update(ctx context.Context, request web.CategoryUpdateRequest) web.CategoryResponse
-	Delete(ctx context.Context, categoryId int)
-	FindById(ctx context.Context, categoryId int) web.CategoryResponse
-	FindAll(ctx context.Context) []web.CategoryResponse
+	CrudService[web.CategoryCreateRequest, web.CategoryUpdateRequest, web.CategoryResponse]
+	DeleteCascade(ctx context.Context, categoryId int)
+	Import(ctx context.Context, reader io.Reader, request web.CategoryImportRequest) web.CategoryImportResponse
+	// Export calls fn with every category of the tenant as it is read, without product counts.
+	Export(ctx context.Context, fn func(categoryResponse web.CategoryResponse))
 }
@@ -3,6 +3,10 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/mrakhaf/golang-restful-api/exeption"
@@ -12,91 +16,200 @@ import (
 	"github.com/mrakhaf/golang-restful-api/repository"
 )
 
+// CategoryServiceImpl takes Create, Update, FindById and FindAll from CrudServiceImpl
+// and only implements what is specific to categories.
 type CategoryServiceImpl struct {
+	*CrudServiceImpl[domain.Category, web.CategoryCreateRequest, web.CategoryUpdateRequest, web.CategoryResponse]
 	CategoryRepository repository.CategoryRepository
-	DB                 *sql.DB
-	Validate           *validator.Validate
+	ProductRepository  repository.ProductRepository
 }
 
 // Constructor for CategoryServiceImpl
-func NewCategoryService(CategoryRepository repository.CategoryRepository, DB *sql.DB, Validate *validator.Validate) CategoryService {
-	return &CategoryServiceImpl{CategoryRepository: CategoryRepository, DB: DB, Validate: Validate}
-}
-
-func (service *CategoryServiceImpl) Create(ctx context.Context, request web.CategoryCreateRequest) web.CategoryResponse {
-	//validate
-	err := service.Validate.Struct(request)
-	helper.PanicIfError(err)
-
-	tx, err := service.DB.Begin()
-	helper.PanicIfError(err)
-	defer helper.CommitOrRollback(tx)
-
-	category := domain.Category{
-		Id:   0,
-		Name: request.Name,
+func NewCategoryService(CategoryRepository repository.CategoryRepository, ProductRepository repository.ProductRepository, DB *sql.DB, Validate *validator.Validate) CategoryService {
+	service := &CategoryServiceImpl{CategoryRepository: CategoryRepository, ProductRepository: ProductRepository}
+	service.CrudServiceImpl = &CrudServiceImpl[domain.Category, web.CategoryCreateRequest, web.CategoryUpdateRequest, web.CategoryResponse]{
+		Repository: CategoryRepository,
+		Resource: CrudResource[domain.Category, web.CategoryCreateRequest, web.CategoryUpdateRequest, web.CategoryResponse]{
+			FromCreateRequest: func(request web.CategoryCreateRequest) domain.Category {
+				return domain.Category{Name: request.Name}
+			},
+			FromUpdateRequest: func(category domain.Category, request web.CategoryUpdateRequest) domain.Category {
+				category.Name = request.Name
+				return category
+			},
+			UpdateRequestId: func(request web.CategoryUpdateRequest) int {
+				return request.Id
+			},
+			ToResponse: helper.ToCategoryResponse,
+			Enrich:     service.withProductCounts,
+			NotFound:   exeption.CategoryNotFound,
+			Conflict:   exeption.CategoryNameTaken,
+		},
+		DB:       DB,
+		Validate: Validate,
 	}
+	return service
+}
 
-	res := service.CategoryRepository.Save(ctx, tx, category)
-	category.Id = res.Id
-
-	return helper.ToCategoryResponse(category)
-
+// Delete refuses to remove a category that still has products.
+func (service *CategoryServiceImpl) Delete(ctx context.Context, categoryId int) {
+	service.delete(ctx, categoryId, false)
 }
 
-func (service *CategoryServiceImpl) Update(ctx context.Context, request web.CategoryUpdateRequest) web.CategoryResponse {
-	//validate
-	err := service.Validate.Struct(request)
-	helper.PanicIfError(err)
+// DeleteCascade removes a category together with its products.
+func (service *CategoryServiceImpl) DeleteCascade(ctx context.Context, categoryId int) {
+	service.delete(ctx, categoryId, true)
+}
 
+func (service *CategoryServiceImpl) delete(ctx context.Context, categoryId int, cascade bool) {
 	tx, err := service.DB.Begin()
 	helper.PanicIfError(err)
 	defer helper.CommitOrRollback(tx)
 
-	category, err := service.CategoryRepository.FindById(ctx, tx, request.Id)
+	category, err := service.CategoryRepository.FindById(ctx, tx, categoryId)
 	if err != nil {
-		panic(exeption.NewNotFoundError(err.Error()))
+		panic(exeption.NewError(exeption.CategoryNotFound, err.Error()))
 	}
 
-	category.Name = request.Name
-
-	service.CategoryRepository.Update(ctx, tx, category)
+	productCount := service.ProductRepository.CountByCategoryIds(ctx, tx, []int{category.Id})[category.Id]
+	if productCount > 0 {
+		if !cascade {
+			panic(exeption.NewError(exeption.CategoryHasProducts, fmt.Sprintf("category still has %d products, pass cascade=true to delete them", productCount)))
+		}
+		service.ProductRepository.DeleteByCategoryId(ctx, tx, category.Id)
+	}
 
-	return helper.ToCategoryResponse(category)
+	service.CategoryRepository.Delete(ctx, tx, category)
 }
 
-func (service *CategoryServiceImpl) Delete(ctx context.Context, categoryId int) {
+func (service *CategoryServiceImpl) Export(ctx context.Context, fn func(categoryResponse web.CategoryResponse)) {
 	tx, err := service.DB.Begin()
 	helper.PanicIfError(err)
 	defer helper.CommitOrRollback(tx)
 
-	category, err := service.CategoryRepository.FindById(ctx, tx, categoryId)
-	if err != nil {
-		panic(exeption.NewNotFoundError(err.Error()))
+	service.CategoryRepository.Each(ctx, tx, func(category domain.Category) {
+		fn(helper.ToCategoryResponse(category))
+	})
+}
+
+func (service *CategoryServiceImpl) withProductCounts(ctx context.Context, tx *sql.Tx, categoryResponses []web.CategoryResponse) {
+	categoryIds := make([]int, 0, len(categoryResponses))
+	for _, categoryResponse := range categoryResponses {
+		categoryIds = append(categoryIds, categoryResponse.Id)
 	}
+	productCounts := service.ProductRepository.CountByCategoryIds(ctx, tx, categoryIds)
 
-	service.CategoryRepository.Delete(ctx, tx, category)
+	for i := range categoryResponses {
+		productCount := productCounts[categoryResponses[i].Id]
+		categoryResponses[i].ProductCount = &productCount
+	}
 }
 
-func (service *CategoryServiceImpl) FindById(ctx context.Context, categoryId int) web.CategoryResponse {
-	tx, err := service.DB.Begin()
-	helper.PanicIfError(err)
-	defer helper.CommitOrRollback(tx)
+func (service *CategoryServiceImpl) Import(ctx context.Context, reader io.Reader, request web.CategoryImportRequest) web.CategoryImportResponse {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
 
-	category, err := service.CategoryRepository.FindById(ctx, tx, categoryId)
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		panic(exeption.NewError(exeption.CategoryCsvInvalid, "csv file is empty"))
+	}
 	if err != nil {
-		panic(exeption.NewNotFoundError(err.Error()))
+		panic(exeption.NewError(exeption.CategoryCsvInvalid, err.Error()))
 	}
 
-	return helper.ToCategoryResponse(category)
-}
+	nameColumn := -1
+	for i, column := range header {
+		// spreadsheet exports often prefix the first cell with a UTF-8 byte order mark
+		column = strings.TrimPrefix(column, "\ufeff")
+		if strings.EqualFold(strings.TrimSpace(column), "name") {
+			nameColumn = i
+		}
+	}
+	if nameColumn < 0 {
+		panic(exeption.NewError(exeption.CategoryCsvInvalid, "csv header must contain a name column"))
+	}
 
-func (service *CategoryServiceImpl) FindAll(ctx context.Context) []web.CategoryResponse {
 	tx, err := service.DB.Begin()
 	helper.PanicIfError(err)
-	defer helper.CommitOrRollback(tx)
+	// a dry run applies every row inside the transaction so the report matches a real import, then discards it
+	if request.DryRun {
+		defer tx.Rollback()
+	} else {
+		defer helper.CommitOrRollback(tx)
+	}
+
+	translator := helper.Translator(request.Language)
+	response := web.CategoryImportResponse{
+		DryRun: request.DryRun,
+		Upsert: request.Upsert,
+		Lines:  []web.CategoryImportLineResult{},
+	}
 
-	categories := service.CategoryRepository.FindAll(ctx, tx)
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			parseError, ok := err.(*csv.ParseError)
+			if !ok {
+				panic(err)
+			}
+			response.Failed++
+			response.Lines = append(response.Lines, web.CategoryImportLineResult{
+				Line:   parseError.StartLine,
+				Action: "failed",
+				Error:  parseError.Err.Error(),
+			})
+			continue
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		categoryRequest := web.CategoryCreateRequest{}
+		if nameColumn < len(record) {
+			// names starting like a formula were escaped by the export
+			categoryRequest.Name = helper.UnescapeCsvCell(strings.TrimSpace(record[nameColumn]))
+		}
+
+		result := web.CategoryImportLineResult{Line: line, Name: categoryRequest.Name}
+		err = service.Validate.Struct(categoryRequest)
+		if err != nil {
+			result.Action = "failed"
+			result.Error = err.Error()
+			if validationErrors, ok := err.(validator.ValidationErrors); ok {
+				result.Error = exeption.ValidationFailed.Message
+				result.Errors = helper.ToValidationErrors(validationErrors, translator)
+			}
+			response.Failed++
+			response.Lines = append(response.Lines, result)
+			continue
+		}
+
+		category := domain.Category{Name: categoryRequest.Name}
+		existing, err := service.CategoryRepository.FindByName(ctx, tx, category.Name)
+		exists := err == nil
+		if exists && !request.Upsert {
+			result.Action = "failed"
+			result.Error = exeption.CategoryNameTaken.Message
+			response.Failed++
+			response.Lines = append(response.Lines, result)
+			continue
+		}
+		category.Id = existing.Id
+
+		if exists {
+			service.CategoryRepository.Update(ctx, tx, category)
+			result.Action = "updated"
+			response.Updated++
+		} else {
+			service.CategoryRepository.Save(ctx, tx, category)
+			result.Action = "created"
+			response.Created++
+		}
+		response.Lines = append(response.Lines, result)
+	}
 
-	return helper.ToCategoryResponses(categories)
+	response.Total = len(response.Lines)
+	return response
 }
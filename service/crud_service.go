@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mrakhaf/golang-restful-api/exeption"
+)
+
+type CrudService[C any, U any, R any] interface {
+	Create(ctx context.Context, request C) R
+	Update(ctx context.Context, request U) R
+	Delete(ctx context.Context, id int)
+	FindById(ctx context.Context, id int) R
+	FindAll(ctx context.Context) []R
+}
+
+// CrudResource declares the conversions between a domain type T, its create request C,
+// update request U and response R. Validation comes from the validate tags on C and U.
+type CrudResource[T any, C any, U any, R any] struct {
+	FromCreateRequest func(request C) T
+	// FromUpdateRequest applies request onto the stored entity.
+	FromUpdateRequest func(entity T, request U) T
+	UpdateRequestId   func(request U) int
+	ToResponse        func(entity T) R
+	// Enrich optionally fills read responses with data from other repositories, inside the same transaction.
+	Enrich func(ctx context.Context, tx *sql.Tx, responses []R)
+	// NotFound is the error code returned for a missing id, exeption.NotFound when unset.
+	NotFound exeption.ErrorCode
+	// Conflict is the error code returned when a unique index rejects a create or update, left unset
+	// the database error is answered as an internal error.
+	Conflict exeption.ErrorCode
+}
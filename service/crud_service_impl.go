@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/repository"
+)
+
+type CrudServiceImpl[T any, C any, U any, R any] struct {
+	Repository repository.CrudRepository[T]
+	Resource   CrudResource[T, C, U, R]
+	DB         *sql.DB
+	Validate   *validator.Validate
+}
+
+// Constructor for CrudServiceImpl
+func NewCrudService[T any, C any, U any, R any](Repository repository.CrudRepository[T], Resource CrudResource[T, C, U, R], DB *sql.DB, Validate *validator.Validate) CrudService[C, U, R] {
+	return &CrudServiceImpl[T, C, U, R]{Repository: Repository, Resource: Resource, DB: DB, Validate: Validate}
+}
+
+func (service *CrudServiceImpl[T, C, U, R]) Create(ctx context.Context, request C) R {
+	//validate
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+	defer conflictOnDuplicateKey(service.Resource.Conflict)
+
+	entity := service.Repository.Save(ctx, tx, service.Resource.FromCreateRequest(request))
+
+	return service.Resource.ToResponse(entity)
+}
+
+func (service *CrudServiceImpl[T, C, U, R]) Update(ctx context.Context, request U) R {
+	//validate
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+	defer conflictOnDuplicateKey(service.Resource.Conflict)
+
+	entity, err := service.Repository.FindById(ctx, tx, service.Resource.UpdateRequestId(request))
+	if err != nil {
+		panic(exeption.NewError(service.notFound(), err.Error()))
+	}
+
+	entity = service.Repository.Update(ctx, tx, service.Resource.FromUpdateRequest(entity, request))
+
+	return service.Resource.ToResponse(entity)
+}
+
+func (service *CrudServiceImpl[T, C, U, R]) Delete(ctx context.Context, id int) {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	entity, err := service.Repository.FindById(ctx, tx, id)
+	if err != nil {
+		panic(exeption.NewError(service.notFound(), err.Error()))
+	}
+
+	service.Repository.Delete(ctx, tx, entity)
+}
+
+func (service *CrudServiceImpl[T, C, U, R]) FindById(ctx context.Context, id int) R {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	entity, err := service.Repository.FindById(ctx, tx, id)
+	if err != nil {
+		panic(exeption.NewError(service.notFound(), err.Error()))
+	}
+
+	responses := []R{service.Resource.ToResponse(entity)}
+	service.enrich(ctx, tx, responses)
+
+	return responses[0]
+}
+
+func (service *CrudServiceImpl[T, C, U, R]) FindAll(ctx context.Context) []R {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	responses := helper.ToResponses(service.Repository.FindAll(ctx, tx), service.Resource.ToResponse)
+	service.enrich(ctx, tx, responses)
+
+	return responses
+}
+
+func (service *CrudServiceImpl[T, C, U, R]) notFound() exeption.ErrorCode {
+	if service.Resource.NotFound.Code == "" {
+		return exeption.NotFound
+	}
+	return service.Resource.NotFound
+}
+
+func (service *CrudServiceImpl[T, C, U, R]) enrich(ctx context.Context, tx *sql.Tx, responses []R) {
+	if service.Resource.Enrich != nil && len(responses) > 0 {
+		service.Resource.Enrich(ctx, tx, responses)
+	}
+}
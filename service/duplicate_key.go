@@ -0,0 +1,19 @@
+package service
+
+import (
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/repository"
+)
+
+// conflictOnDuplicateKey is deferred after helper.CommitOrRollback, so it sees the panic of a
+// statement rejected by a unique index first and answers it with code instead.
+func conflictOnDuplicateKey(code exeption.ErrorCode) {
+	err := recover()
+	if err == nil {
+		return
+	}
+	if code.Code != "" && repository.IsDuplicateKey(err) {
+		panic(exeption.NewError(code, ""))
+	}
+	panic(err)
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+type OauthService interface {
+	RegisterClient(ctx context.Context, request web.OauthClientCreateRequest) web.OauthClientRegisterResponse
+	FindAllClients(ctx context.Context) []web.OauthClientResponse
+	RevokeClient(ctx context.Context, id int)
+	Token(ctx context.Context, request web.OauthTokenRequest) web.OauthTokenResponse
+	Introspect(ctx context.Context, request web.OauthTokenLookupRequest) web.OauthIntrospectResponse
+	Revoke(ctx context.Context, request web.OauthTokenLookupRequest)
+	// Issued reports whether token claims to come from this server, without verifying it.
+	Issued(token string) bool
+	Authenticate(ctx context.Context, token string) (web.Principal, error)
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/repository"
+)
+
+// OauthTokenConfig signs the HS256 access tokens handed out by the token endpoint.
+type OauthTokenConfig struct {
+	SigningKey []byte
+	Issuer     string
+	TTL        time.Duration
+}
+
+type oauthClaims struct {
+	jwt.RegisteredClaims
+	ClientId string `json:"client_id"`
+	Tenant   string `json:"tenant"`
+	Scope    string `json:"scope"`
+}
+
+type OauthServiceImpl struct {
+	OauthClientRepository repository.OauthClientRepository
+	OauthTokenRepository  repository.OauthTokenRepository
+	DB                    *sql.DB
+	Validate              *validator.Validate
+	Config                OauthTokenConfig
+}
+
+// Constructor for OauthServiceImpl
+func NewOauthService(OauthClientRepository repository.OauthClientRepository, OauthTokenRepository repository.OauthTokenRepository, DB *sql.DB, Validate *validator.Validate, Config OauthTokenConfig) OauthService {
+	return &OauthServiceImpl{OauthClientRepository: OauthClientRepository, OauthTokenRepository: OauthTokenRepository, DB: DB, Validate: Validate, Config: Config}
+}
+
+func (service *OauthServiceImpl) RegisterClient(ctx context.Context, request web.OauthClientCreateRequest) web.OauthClientRegisterResponse {
+	//validate
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	secret := "cs_" + base64.RawURLEncoding.EncodeToString(randomBytes(32))
+	client := service.OauthClientRepository.Save(ctx, tx, domain.OauthClient{
+		ClientId:   "cl_" + hex.EncodeToString(randomBytes(12)),
+		Name:       request.Name,
+		SecretHash: HashApiKey(secret),
+		Scopes:     strings.Join(request.Scopes, " "),
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+	})
+
+	return web.OauthClientRegisterResponse{
+		OauthClientResponse: helper.ToOauthClientResponse(client),
+		ClientSecret:        secret,
+	}
+}
+
+func (service *OauthServiceImpl) FindAllClients(ctx context.Context) []web.OauthClientResponse {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	clients := service.OauthClientRepository.FindAll(ctx, tx)
+
+	return helper.ToResponses(clients, helper.ToOauthClientResponse)
+}
+
+// RevokeClient also deactivates every token already issued to the client.
+func (service *OauthServiceImpl) RevokeClient(ctx context.Context, id int) {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	client, err := service.OauthClientRepository.FindById(ctx, tx, id)
+	if err != nil {
+		panic(exeption.NewError(exeption.OauthClientNotFound, err.Error()))
+	}
+
+	client.Revoked = true
+	service.OauthClientRepository.Update(ctx, tx, client)
+}
+
+func (service *OauthServiceImpl) Token(ctx context.Context, request web.OauthTokenRequest) web.OauthTokenResponse {
+	if request.GrantType == "" {
+		panic(exeption.NewOauthError(exeption.OauthInvalidRequest, "grant_type is required"))
+	}
+	if request.GrantType != "client_credentials" {
+		panic(exeption.NewOauthError(exeption.OauthUnsupportedGrantType, "only client_credentials is supported"))
+	}
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	client := service.authenticateClient(ctx, tx, request.OauthClientCredentials)
+
+	scope := client.Scopes
+	if request.Scope != "" {
+		granted := strings.Fields(client.Scopes)
+		for _, requested := range strings.Fields(request.Scope) {
+			if !containsString(granted, requested) {
+				panic(exeption.NewOauthError(exeption.OauthInvalidScope, "scope "+requested+" is not granted to the client"))
+			}
+		}
+		scope = strings.Join(strings.Fields(request.Scope), " ")
+	}
+
+	now := time.Now()
+	claims := oauthClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(randomBytes(16)),
+			Issuer:    service.Config.Issuer,
+			Subject:   client.ClientId,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(service.Config.TTL)),
+		},
+		ClientId: client.ClientId,
+		Tenant:   client.TenantId,
+		Scope:    scope,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(service.Config.SigningKey)
+	helper.PanicIfError(err)
+
+	return web.OauthTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(service.Config.TTL.Seconds()),
+		Scope:       scope,
+	}
+}
+
+// Introspect only reveals tokens of the caller's own tenant, any other token is reported inactive.
+func (service *OauthServiceImpl) Introspect(ctx context.Context, request web.OauthTokenLookupRequest) web.OauthIntrospectResponse {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	client := service.authenticateClient(ctx, tx, request.OauthClientCredentials)
+
+	claims, err := service.verify(ctx, tx, request.Token)
+	if err != nil || claims.Tenant != client.TenantId {
+		return web.OauthIntrospectResponse{Active: false}
+	}
+
+	return web.OauthIntrospectResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientId:  claims.ClientId,
+		Subject:   claims.Subject,
+		Tenant:    claims.Tenant,
+		TokenType: "Bearer",
+		Issuer:    claims.Issuer,
+		IssuedAt:  claims.IssuedAt.Unix(),
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		Id:        claims.ID,
+	}
+}
+
+// Revoke follows RFC 7009: tokens that are invalid or already expired are silently accepted.
+func (service *OauthServiceImpl) Revoke(ctx context.Context, request web.OauthTokenLookupRequest) {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	client := service.authenticateClient(ctx, tx, request.OauthClientCredentials)
+
+	claims, err := service.verify(ctx, tx, request.Token)
+	if err != nil {
+		return
+	}
+	if claims.ClientId != client.ClientId {
+		panic(exeption.NewOauthError(exeption.OauthUnauthorizedClient, "token was issued to another client"))
+	}
+
+	service.OauthTokenRepository.DeleteExpired(ctx, tx, time.Now().UTC())
+	service.OauthTokenRepository.Revoke(ctx, tx, claims.ID, claims.ClientId, claims.ExpiresAt.UTC())
+}
+
+func (service *OauthServiceImpl) Issued(token string) bool {
+	claims := oauthClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(token, &claims)
+	return err == nil && claims.Issuer == service.Config.Issuer
+}
+
+func (service *OauthServiceImpl) Authenticate(ctx context.Context, token string) (web.Principal, error) {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	claims, err := service.verify(ctx, tx, token)
+	if err != nil {
+		return web.Principal{}, err
+	}
+
+	return web.Principal{
+		Kind:     "oauth_client",
+		Id:       claims.ClientId,
+		Name:     claims.ClientId,
+		TenantId: claims.Tenant,
+		Scopes:   strings.Fields(claims.Scope),
+		Claims: map[string]interface{}{
+			"iss":       claims.Issuer,
+			"sub":       claims.Subject,
+			"jti":       claims.ID,
+			"client_id": claims.ClientId,
+			"tenant":    claims.Tenant,
+			"scope":     claims.Scope,
+		},
+	}, nil
+}
+
+// verify checks the signature and expiry of an access token and that neither it nor its client was revoked.
+func (service *OauthServiceImpl) verify(ctx context.Context, tx *sql.Tx, token string) (oauthClaims, error) {
+	claims := oauthClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		return service.Config.SigningKey, nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithIssuer(service.Config.Issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return claims, err
+	}
+
+	if service.OauthTokenRepository.IsRevoked(ctx, tx, claims.ID) {
+		return claims, errors.New("token is revoked")
+	}
+
+	client, err := service.OauthClientRepository.FindByClientId(ctx, tx, claims.ClientId)
+	if err != nil || client.Revoked {
+		return claims, errors.New("client is revoked")
+	}
+
+	return claims, nil
+}
+
+func (service *OauthServiceImpl) authenticateClient(ctx context.Context, tx *sql.Tx, credentials web.OauthClientCredentials) domain.OauthClient {
+	if credentials.ClientId == "" || credentials.ClientSecret == "" {
+		panic(exeption.NewOauthError(exeption.OauthInvalidClient, "client authentication is required"))
+	}
+
+	client, err := service.OauthClientRepository.FindByClientId(ctx, tx, credentials.ClientId)
+	// client secrets are random like api keys, so they are hashed the same way
+	if err != nil || client.Revoked || subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(HashApiKey(credentials.ClientSecret))) != 1 {
+		panic(exeption.NewOauthError(exeption.OauthInvalidClient, "client authentication failed"))
+	}
+
+	return client
+}
+
+func randomBytes(n int) []byte {
+	bytes := make([]byte, n)
+	_, err := rand.Read(bytes)
+	helper.PanicIfError(err)
+	return bytes
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
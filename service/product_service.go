@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+type ProductService interface {
+	Create(ctx context.Context, request web.ProductCreateRequest) web.ProductResponse
+	Update(ctx context.Context, request web.ProductUpdateRequest) web.ProductResponse
+	Delete(ctx context.Context, productId int)
+	FindById(ctx context.Context, productId int) web.ProductResponse
+	FindAll(ctx context.Context) []web.ProductResponse
+	FindByCategoryId(ctx context.Context, categoryId int) []web.ProductResponse
+}
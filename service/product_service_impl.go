@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/repository"
+)
+
+type ProductServiceImpl struct {
+	ProductRepository  repository.ProductRepository
+	CategoryRepository repository.CategoryRepository
+	DB                 *sql.DB
+	Validate           *validator.Validate
+}
+
+// Constructor for ProductServiceImpl
+func NewProductService(ProductRepository repository.ProductRepository, CategoryRepository repository.CategoryRepository, DB *sql.DB, Validate *validator.Validate) ProductService {
+	return &ProductServiceImpl{ProductRepository: ProductRepository, CategoryRepository: CategoryRepository, DB: DB, Validate: Validate}
+}
+
+func (service *ProductServiceImpl) Create(ctx context.Context, request web.ProductCreateRequest) web.ProductResponse {
+	//validate
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	service.mustFindCategory(ctx, tx, request.CategoryId)
+
+	product := service.ProductRepository.Save(ctx, tx, domain.Product{
+		CategoryId: request.CategoryId,
+		Name:       request.Name,
+		Price:      request.Price,
+	})
+
+	return helper.ToProductResponse(product)
+}
+
+func (service *ProductServiceImpl) Update(ctx context.Context, request web.ProductUpdateRequest) web.ProductResponse {
+	//validate
+	err := service.Validate.Struct(request)
+	helper.PanicIfError(err)
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	product, err := service.ProductRepository.FindById(ctx, tx, request.Id)
+	if err != nil {
+		panic(exeption.NewError(exeption.ProductNotFound, err.Error()))
+	}
+
+	service.mustFindCategory(ctx, tx, request.CategoryId)
+
+	product.CategoryId = request.CategoryId
+	product.Name = request.Name
+	product.Price = request.Price
+
+	service.ProductRepository.Update(ctx, tx, product)
+
+	return helper.ToProductResponse(product)
+}
+
+func (service *ProductServiceImpl) Delete(ctx context.Context, productId int) {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	product, err := service.ProductRepository.FindById(ctx, tx, productId)
+	if err != nil {
+		panic(exeption.NewError(exeption.ProductNotFound, err.Error()))
+	}
+
+	service.ProductRepository.Delete(ctx, tx, product)
+}
+
+func (service *ProductServiceImpl) FindById(ctx context.Context, productId int) web.ProductResponse {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	product, err := service.ProductRepository.FindById(ctx, tx, productId)
+	if err != nil {
+		panic(exeption.NewError(exeption.ProductNotFound, err.Error()))
+	}
+
+	return helper.ToProductResponse(product)
+}
+
+func (service *ProductServiceImpl) FindAll(ctx context.Context) []web.ProductResponse {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	products := service.ProductRepository.FindAll(ctx, tx)
+
+	return helper.ToProductResponses(products)
+}
+
+func (service *ProductServiceImpl) FindByCategoryId(ctx context.Context, categoryId int) []web.ProductResponse {
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	_, err = service.CategoryRepository.FindById(ctx, tx, categoryId)
+	if err != nil {
+		panic(exeption.NewError(exeption.CategoryNotFound, err.Error()))
+	}
+
+	products := service.ProductRepository.FindByCategoryId(ctx, tx, categoryId)
+
+	return helper.ToProductResponses(products)
+}
+
+// mustFindCategory rejects a category_id in the request body that does not exist for the tenant.
+func (service *ProductServiceImpl) mustFindCategory(ctx context.Context, tx *sql.Tx, categoryId int) {
+	_, err := service.CategoryRepository.FindById(ctx, tx, categoryId)
+	if err != nil {
+		panic(exeption.NewError(exeption.ProductCategoryInvalid, err.Error()))
+	}
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/model/web"
+)
+
+type UsageService interface {
+	// Count records a request of principal in the read or write route class. When a quota is already
+	// used up the request is not counted, and the exceeded period ("daily" or "monthly") and its reset are returned.
+	Count(ctx context.Context, principal web.Principal, routeClass string) (exceeded string, resetAt time.Time)
+	// Flush adds the counters collected since the last flush to the database and reloads the totals
+	// checked against the quotas, taking in the requests the other instances flushed.
+	Flush(ctx context.Context)
+	// FlushEvery flushes every interval until ctx is done, and once more then.
+	FlushEvery(ctx context.Context, interval time.Duration)
+	// FindOwn returns the usage of the current month of the credential of the request.
+	FindOwn(ctx context.Context) web.UsageResponse
+}
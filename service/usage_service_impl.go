@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/repository"
+)
+
+// UsageQuota limits the requests of a credential per UTC day and month, zero means unlimited.
+type UsageQuota struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+type usageKey struct {
+	tenantId   string
+	credential string
+	routeClass string
+	day        string
+}
+
+type usageTotalKey struct {
+	tenantId   string
+	credential string
+}
+
+// usageTotal is what a credential used in the current day and month, loaded from the database and
+// kept up to date in memory, so quotas are checked without a query per request. Every Flush reloads it
+// to take in what the other instances flushed, so a quota may be exceeded by the requests the other
+// instances count within one flush interval.
+type usageTotal struct {
+	day        string
+	dayCount   int64
+	month      string
+	monthCount int64
+}
+
+type UsageServiceImpl struct {
+	UsageRepository repository.UsageRepository
+	DB              *sql.DB
+	Default         UsageQuota
+	// Quotas overrides Default per credential, keyed like "api_key:12".
+	Quotas map[string]UsageQuota
+
+	mutex   sync.Mutex
+	pending map[usageKey]int64
+	totals  map[usageTotalKey]*usageTotal
+	// flushing keeps a reload from missing the counters of a flush running at the same time
+	flushing sync.Mutex
+}
+
+// Constructor for UsageServiceImpl
+func NewUsageService(UsageRepository repository.UsageRepository, DB *sql.DB, Default UsageQuota, Quotas map[string]UsageQuota) UsageService {
+	return &UsageServiceImpl{
+		UsageRepository: UsageRepository,
+		DB:              DB,
+		Default:         Default,
+		Quotas:          Quotas,
+		pending:         map[usageKey]int64{},
+		totals:          map[usageTotalKey]*usageTotal{},
+	}
+}
+
+func CredentialKey(principal web.Principal) string {
+	return principal.Kind + ":" + principal.Id
+}
+
+func (service *UsageServiceImpl) Count(ctx context.Context, principal web.Principal, routeClass string) (string, time.Time) {
+	now := time.Now().UTC()
+	day, month := now.Format("2006-01-02"), now.Format("2006-01")
+	credential := CredentialKey(principal)
+	totalKey := usageTotalKey{tenantId: principal.TenantId, credential: credential}
+
+	service.mutex.Lock()
+	total, ok := service.totals[totalKey]
+	service.mutex.Unlock()
+	if !ok {
+		// loaded without holding the lock, a concurrent first request of the same credential may load it too
+		loaded, err := service.load(helper.WithTenant(ctx, principal.TenantId), credential, now)
+		if err != nil {
+			// metering must not take the api down with the database, the request is counted and the load retried
+			helper.Logger(ctx).Error("loading usage failed", "credential", credential, "error", err)
+			service.mutex.Lock()
+			service.pending[usageKey{tenantId: principal.TenantId, credential: credential, routeClass: routeClass, day: day}]++
+			service.mutex.Unlock()
+			return "", time.Time{}
+		}
+
+		service.mutex.Lock()
+		if total, ok = service.totals[totalKey]; !ok {
+			total = loaded
+			service.totals[totalKey] = total
+		}
+		service.mutex.Unlock()
+	}
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if total.month != month {
+		total.month, total.monthCount = month, 0
+	}
+	if total.day != day {
+		total.day, total.dayCount = day, 0
+	}
+
+	quota, ok := service.Quotas[credential]
+	if !ok {
+		quota = service.Default
+	}
+	if quota.Daily > 0 && total.dayCount >= quota.Daily {
+		return "daily", time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	}
+	if quota.Monthly > 0 && total.monthCount >= quota.Monthly {
+		return "monthly", time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	total.dayCount++
+	total.monthCount++
+	service.pending[usageKey{tenantId: principal.TenantId, credential: credential, routeClass: routeClass, day: day}]++
+	return "", time.Time{}
+}
+
+func (service *UsageServiceImpl) load(ctx context.Context, credential string, now time.Time) (total *usageTotal, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	total = &usageTotal{day: now.Format("2006-01-02"), month: now.Format("2006-01")}
+	for _, usage := range service.UsageRepository.FindByCredential(ctx, tx, credential, monthStart(now)) {
+		total.monthCount += usage.RequestCount
+		if usage.Day.Format("2006-01-02") == total.day {
+			total.dayCount += usage.RequestCount
+		}
+	}
+	return total, nil
+}
+
+func (service *UsageServiceImpl) Flush(ctx context.Context) {
+	service.flushing.Lock()
+	defer service.flushing.Unlock()
+
+	service.write(ctx)
+	service.reload(ctx)
+}
+
+// write adds the pending counters to the database.
+func (service *UsageServiceImpl) write(ctx context.Context) {
+	service.mutex.Lock()
+	pending := service.pending
+	service.pending = map[usageKey]int64{}
+	service.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	// put the counters back when they could not be written, the next flush retries them
+	defer func() {
+		if err := recover(); err != nil {
+			service.mutex.Lock()
+			for key, count := range pending {
+				service.pending[key] += count
+			}
+			service.mutex.Unlock()
+			panic(err)
+		}
+	}()
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	for key, count := range pending {
+		day, err := time.Parse("2006-01-02", key.day)
+		helper.PanicIfError(err)
+
+		service.UsageRepository.Increment(ctx, tx, domain.Usage{
+			TenantId:     key.tenantId,
+			Credential:   key.credential,
+			RouteClass:   key.routeClass,
+			Day:          day,
+			RequestCount: count,
+		})
+	}
+}
+
+// reload replaces the totals with the database counters, plus what was counted since they were written.
+func (service *UsageServiceImpl) reload(ctx context.Context) {
+	now := time.Now().UTC()
+	day, month := now.Format("2006-01-02"), now.Format("2006-01")
+	service.mutex.Lock()
+	var totalKeys []usageTotalKey
+	for totalKey, total := range service.totals {
+		// totals of a past month are reloaded when their credential is seen again, which keeps
+		// the map to the credentials of the current month
+		if total.month != month {
+			delete(service.totals, totalKey)
+		} else {
+			totalKeys = append(totalKeys, totalKey)
+		}
+	}
+	service.mutex.Unlock()
+
+	for _, totalKey := range totalKeys {
+		loaded, err := service.load(helper.WithTenant(ctx, totalKey.tenantId), totalKey.credential, now)
+		if err != nil {
+			// the total in memory stays in use until the next flush
+			helper.Logger(ctx).Error("reloading usage failed", "credential", totalKey.credential, "error", err)
+			continue
+		}
+
+		service.mutex.Lock()
+		for key, count := range service.pending {
+			if key.tenantId != totalKey.tenantId || key.credential != totalKey.credential {
+				continue
+			}
+			if key.day == day {
+				loaded.dayCount += count
+			}
+			if strings.HasPrefix(key.day, month) {
+				loaded.monthCount += count
+			}
+		}
+		// updated in place, as Count may hold the total between its locks
+		if total, ok := service.totals[totalKey]; ok {
+			*total = *loaded
+		}
+		service.mutex.Unlock()
+	}
+}
+
+func (service *UsageServiceImpl) FlushEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			service.flushLogged(ctx)
+		case <-ctx.Done():
+			service.flushLogged(context.Background())
+			return
+		}
+	}
+}
+
+func (service *UsageServiceImpl) flushLogged(ctx context.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			slog.Error("flushing usage counters failed", "error", err)
+		}
+	}()
+	service.Flush(ctx)
+}
+
+func (service *UsageServiceImpl) FindOwn(ctx context.Context) web.UsageResponse {
+	principal, _ := helper.PrincipalFromContext(ctx)
+	credential := CredentialKey(principal)
+
+	// the counters of this instance are written first, so the response includes its latest requests
+	service.flushing.Lock()
+	service.write(ctx)
+	service.flushing.Unlock()
+
+	tx, err := service.DB.Begin()
+	helper.PanicIfError(err)
+	defer helper.CommitOrRollback(tx)
+
+	now := time.Now().UTC()
+	response := web.UsageResponse{
+		Credential: credential,
+		Today:      web.UsageCount{Period: now.Format("2006-01-02")},
+		Month:      web.UsageCount{Period: now.Format("2006-01")},
+		Days:       []web.UsageCount{},
+	}
+
+	for _, usage := range service.UsageRepository.FindByCredential(ctx, tx, credential, monthStart(now)) {
+		period := usage.Day.Format("2006-01-02")
+		if len(response.Days) == 0 || response.Days[len(response.Days)-1].Period != period {
+			response.Days = append(response.Days, web.UsageCount{Period: period})
+		}
+		addUsage(&response.Days[len(response.Days)-1], usage)
+		addUsage(&response.Month, usage)
+		if period == response.Today.Period {
+			addUsage(&response.Today, usage)
+		}
+	}
+
+	quota, ok := service.Quotas[credential]
+	if !ok {
+		quota = service.Default
+	}
+	response.Quota = web.UsageQuotaResponse{Daily: quota.Daily, Monthly: quota.Monthly}
+
+	return response
+}
+
+func addUsage(count *web.UsageCount, usage domain.Usage) {
+	if usage.RouteClass == "read" {
+		count.Read += usage.RequestCount
+	} else {
+		count.Write += usage.RequestCount
+	}
+	count.Total += usage.RequestCount
+}
+
+func monthStart(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
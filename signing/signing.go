@@ -0,0 +1,130 @@
+// Package signing computes the HMAC request signatures accepted by middleware.HmacAuthenticator.
+// Clients can use Sign or NewTransport to sign their requests with a shared secret.
+package signing
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderKeyId     = "X-Signature-Key-Id"
+	HeaderTimestamp = "X-Signature-Timestamp"
+	HeaderNonce     = "X-Signature-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// StringToSign joins the signed parts of a request, one per line.
+func StringToSign(method string, path string, query url.Values, bodyHash string, timestamp string, nonce string) string {
+	return strings.Join([]string{strings.ToUpper(method), path, CanonicalQuery(query), bodyHash, timestamp, nonce}, "\n")
+}
+
+// CanonicalQuery encodes the query sorted by key and then by value, so parameter order does not matter.
+func CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func BodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Signature is the hex encoded HMAC-SHA256 of stringToSign.
+func Signature(secret []byte, stringToSign string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign sets the signature headers on request with the current time and a random nonce.
+// The body is read and replaced, and GetBody set, so it can still be sent, and resent on a redirect or retry.
+func Sign(request *http.Request, keyId string, secret []byte) error {
+	var body []byte
+	if request.Body != nil && request.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(request.Body)
+		if err != nil {
+			return err
+		}
+		request.Body.Close()
+		// like http.NewRequest, an empty body is NoBody so it is not sent chunked
+		request.ContentLength = int64(len(body))
+		request.GetBody = func() (io.ReadCloser, error) {
+			if len(body) == 0 {
+				return http.NoBody, nil
+			}
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		request.Body, _ = request.GetBody()
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	stringToSign := StringToSign(request.Method, request.URL.EscapedPath(), request.URL.Query(), BodyHash(body), timestamp, hex.EncodeToString(nonce))
+
+	request.Header.Set(HeaderKeyId, keyId)
+	request.Header.Set(HeaderTimestamp, timestamp)
+	request.Header.Set(HeaderNonce, hex.EncodeToString(nonce))
+	request.Header.Set(HeaderSignature, Signature(secret, stringToSign))
+	return nil
+}
+
+// Transport signs every request before handing it to Base, http.DefaultTransport when nil.
+type Transport struct {
+	Base   http.RoundTripper
+	KeyId  string
+	Secret []byte
+}
+
+func NewTransport(base http.RoundTripper, keyId string, secret []byte) *Transport {
+	return &Transport{Base: base, KeyId: keyId, Secret: secret}
+}
+
+func (transport *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	// a RoundTripper must not modify the request it was given, so the body is read from a fresh copy when there is one
+	request = request.Clone(request.Context())
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		request.Body = body
+	}
+	if err := Sign(request, transport.KeyId, transport.Secret); err != nil {
+		return nil, err
+	}
+
+	base := transport.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(request)
+}
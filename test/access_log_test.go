@@ -0,0 +1,99 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAccessLogHandler(output *bytes.Buffer, level slog.Level, accessLogConfig middleware.AccessLogConfig) http.Handler {
+	logger := slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: level}))
+	authMiddleware := middleware.NewAuthMiddleware(middleware.NewApiKeyAuthenticator(map[string]string{"rahasia": config.DefaultTenant}, nil, nil))
+
+	router := httprouter.New()
+	router.PanicHandler = exeption.ErrorHandler
+	routes := config.Routes{Router: router, Chain: middleware.NewChain(authMiddleware)}
+	routes.GET("/api/categories/:categoryId", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		writer.Write([]byte("category " + params.ByName("categoryId")))
+	})
+
+	return middleware.NewChain(middleware.NewRequestIdMiddleware(), middleware.NewAccessLogMiddleware(logger, accessLogConfig)).Then(router)
+}
+
+func serveAccessLog(handler http.Handler, apiKey string) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/7", nil)
+	request.RemoteAddr = "10.0.0.1:5000"
+	request.Header.Add("X-Request-ID", "trace-1")
+	if apiKey != "" {
+		request.Header.Add("X-API-Key", apiKey)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+}
+
+func logRecords(output *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := map[string]interface{}{}
+		json.Unmarshal([]byte(line), &record)
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAccessLogRecord(t *testing.T) {
+	output := &bytes.Buffer{}
+	serveAccessLog(setupAccessLogHandler(output, slog.LevelInfo, config.NewAccessLogConfig(config.DefaultAppConfig().Log)), "rahasia")
+
+	records := logRecords(output)
+	assert.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "GET", record["method"])
+	assert.Equal(t, "/api/categories/:categoryId", record["route"])
+	assert.Equal(t, float64(200), record["status"])
+	assert.Equal(t, float64(len("category 7")), record["bytes"])
+	assert.Equal(t, "10.0.0.1", record["client_ip"])
+	assert.True(t, strings.HasPrefix(record["principal"].(string), "api_key:static-"))
+	assert.Equal(t, config.DefaultTenant, record["tenant"])
+	assert.Equal(t, "trace-1", record["request_id"])
+	assert.Contains(t, record, "latency")
+
+	headers := record["headers"].(map[string]interface{})
+	assert.Equal(t, "[REDACTED]", headers["X-Api-Key"])
+	assert.NotContains(t, output.String(), "rahasia")
+}
+
+func TestAccessLogLevelAndSampling(t *testing.T) {
+	output := &bytes.Buffer{}
+	handler := setupAccessLogHandler(output, slog.LevelWarn, config.NewAccessLogConfig(config.DefaultAppConfig().Log))
+	serveAccessLog(handler, "rahasia")
+	serveAccessLog(handler, "")
+
+	records := logRecords(output)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "WARN", records[0]["level"])
+	assert.Equal(t, float64(401), records[0]["status"])
+
+	// failed requests are logged whatever the sample rate
+	output.Reset()
+	handler = setupAccessLogHandler(output, slog.LevelInfo, middleware.AccessLogConfig{SampleRate: 0})
+	serveAccessLog(handler, "rahasia")
+	serveAccessLog(handler, "")
+
+	records = logRecords(output)
+	assert.Len(t, records, 1)
+	assert.Equal(t, float64(401), records[0]["status"])
+}
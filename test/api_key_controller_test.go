@@ -0,0 +1,116 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func truncateApiKey() {
+	setupTestDB().Exec("DELETE FROM api_key")
+}
+
+func serve(handler http.Handler, method string, url string, apiKey string, body string) (int, map[string]interface{}) {
+	request := httptest.NewRequest(method, url, strings.NewReader(body))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-API-Key", apiKey)
+
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	responseBody, _ := io.ReadAll(response.Body)
+	var result map[string]interface{}
+	json.Unmarshal(responseBody, &result)
+	fmt.Println(result)
+
+	return response.StatusCode, result
+}
+
+func issueApiKey(t *testing.T) (int, string) {
+	status, body := serve(setupRouter(setupTestDB()), http.MethodPost, "http://localhost:3000/api/admin/api-keys", "rahasia", `{"name": "partner", "owner": "team", "scopes": ["categories:read", "categories:write"]}`)
+	assert.Equal(t, 200, status)
+
+	data := body["data"].(map[string]interface{})
+	return int(data["id"].(float64)), data["key"].(string)
+}
+
+func TestIssuedApiKeyAuthenticates(t *testing.T) {
+	truncateApiKey()
+	_, key := issueApiKey(t)
+
+	status, _ := serve(setupRouter(setupTestDB()), http.MethodGet, "http://localhost:3000/api/categories", key, "")
+	assert.Equal(t, 200, status)
+
+	status, body := serve(setupRouter(setupTestDB()), http.MethodGet, "http://localhost:3000/api/admin/api-keys", "rahasia", "")
+	assert.Equal(t, 200, status)
+
+	apiKeys := body["data"].([]interface{})
+	assert.Equal(t, 1, len(apiKeys))
+	assert.Nil(t, apiKeys[0].(map[string]interface{})["key"])
+	assert.NotNil(t, apiKeys[0].(map[string]interface{})["last_used_at"])
+}
+
+func TestRevokedApiKeyUnauthorized(t *testing.T) {
+	truncateApiKey()
+	id, key := issueApiKey(t)
+
+	status, _ := serve(setupRouter(setupTestDB()), http.MethodPost, "http://localhost:3000/api/admin/api-keys/"+strconv.Itoa(id)+"/revoke", "rahasia", "")
+	assert.Equal(t, 200, status)
+
+	status, _ = serve(setupRouter(setupTestDB()), http.MethodGet, "http://localhost:3000/api/categories", key, "")
+	assert.Equal(t, 401, status)
+}
+
+func TestRotatedApiKeyReplacesOldKey(t *testing.T) {
+	truncateApiKey()
+	id, oldKey := issueApiKey(t)
+
+	status, body := serve(setupRouter(setupTestDB()), http.MethodPost, "http://localhost:3000/api/admin/api-keys/"+strconv.Itoa(id)+"/rotate", "rahasia", "")
+	assert.Equal(t, 200, status)
+	newKey := body["data"].(map[string]interface{})["key"].(string)
+	assert.NotEqual(t, oldKey, newKey)
+
+	status, _ = serve(setupRouter(setupTestDB()), http.MethodGet, "http://localhost:3000/api/categories", oldKey, "")
+	assert.Equal(t, 401, status)
+
+	status, _ = serve(setupRouter(setupTestDB()), http.MethodGet, "http://localhost:3000/api/categories", newKey, "")
+	assert.Equal(t, 200, status)
+}
+
+func TestApiKeyCacheExpires(t *testing.T) {
+	cache := middleware.NewApiKeyCache(10*time.Millisecond, 1)
+	cache.Put("key", web.Principal{TenantId: "default"}, true)
+
+	principal, valid, found := cache.Get("key")
+	assert.True(t, found)
+	assert.True(t, valid)
+	assert.Equal(t, "default", principal.TenantId)
+
+	time.Sleep(20 * time.Millisecond)
+	_, _, found = cache.Get("key")
+	assert.False(t, found)
+}
+
+func TestApiKeyCacheDelete(t *testing.T) {
+	cache := middleware.NewApiKeyCache(time.Minute, 10)
+	cache.Put("hash", web.Principal{TenantId: "default"}, true)
+	cache.Delete("hash")
+
+	_, _, found := cache.Get("hash")
+	assert.False(t, found)
+
+	var disabled *middleware.ApiKeyCache
+	disabled.Delete("hash")
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeAppConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestAppConfigDefaults(t *testing.T) {
+	appConfig, err := config.LoadAppConfig(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, config.DefaultAppConfig(), appConfig)
+	assert.Equal(t, "localhost:3000", appConfig.Server.Addr)
+}
+
+func TestAppConfigLayers(t *testing.T) {
+	path := writeAppConfigFile(t, "app.yaml", `
+database:
+  dsn: app:secret@tcp(db:3306)/shop?parseTime=true
+  max_open_conns: 50
+  max_idle_conns: 10
+server:
+  addr: 0.0.0.0:8080
+  write_timeout: 30s
+auth:
+  jwt_issuer: file
+log:
+  level: debug
+  format: text
+errors:
+  format: problem
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("DB_MAX_OPEN_CONNS", "40")
+	t.Setenv("JWT_ISSUER", "env")
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("SQL_LOG_ARGS", "true")
+
+	appConfig, err := config.LoadAppConfig([]string{"-jwt-issuer", "flag", "-server-read-timeout", "5s", "-log-level", "error", "-app-debug"})
+	assert.Nil(t, err)
+	assert.Equal(t, "app:secret@tcp(db:3306)/shop?parseTime=true", appConfig.Database.Dsn)
+	assert.Equal(t, 40, appConfig.Database.MaxOpenConns)
+	assert.Equal(t, 10, appConfig.Database.MaxIdleConns)
+	assert.Equal(t, 60*time.Minute, appConfig.Database.ConnMaxLifetime)
+	assert.Equal(t, "0.0.0.0:8080", appConfig.Server.Addr)
+	assert.Equal(t, 30*time.Second, appConfig.Server.WriteTimeout)
+	assert.Equal(t, 5*time.Second, appConfig.Server.ReadTimeout)
+	assert.Equal(t, "flag", appConfig.Auth.JwtIssuer)
+	assert.Equal(t, "error", appConfig.Log.Level)
+	assert.Equal(t, "text", appConfig.Log.Format)
+	assert.Equal(t, "problem", appConfig.Errors.Format)
+	assert.True(t, appConfig.Errors.Debug)
+	assert.True(t, appConfig.Database.SqlLogArgs)
+}
+
+func TestAppConfigJsonFile(t *testing.T) {
+	path := writeAppConfigFile(t, "app.json", `{"server": {"addr": ":9000", "idle_timeout": "1m"}, "auth": {"api_key_tenants": "k1:acme"}}`)
+
+	appConfig, err := config.LoadAppConfig([]string{"-config", path})
+	assert.Nil(t, err)
+	assert.Equal(t, ":9000", appConfig.Server.Addr)
+	assert.Equal(t, time.Minute, appConfig.Server.IdleTimeout)
+	assert.Equal(t, map[string]string{"k1": "acme"}, config.NewApiKeyTenants(appConfig.Auth))
+}
+
+func TestAppConfigInvalid(t *testing.T) {
+	path := writeAppConfigFile(t, "app.yaml", "server:\n  adr: localhost:3000\n")
+	_, err := config.LoadAppConfig([]string{"-config", path})
+	assert.ErrorContains(t, err, "field adr not found")
+
+	t.Setenv("DB_MAX_IDLE_CONNS", "many")
+	_, err = config.LoadAppConfig(nil)
+	assert.ErrorContains(t, err, "DB_MAX_IDLE_CONNS")
+
+	t.Setenv("DB_MAX_IDLE_CONNS", "30")
+	_, err = config.LoadAppConfig([]string{"-server-addr", "localhost", "-hmac-keys", "broken", "-oauth-token-ttl", "0s", "-api-key-cache-ttl", "1h"})
+	assert.ErrorContains(t, err, "database.max_idle_conns: must not exceed max_open_conns 20")
+	assert.ErrorContains(t, err, "server.addr: must look like host:port")
+	assert.ErrorContains(t, err, "auth.hmac_keys: HMAC_KEYS entries must look like keyId:secret:tenant:scopes")
+	assert.ErrorContains(t, err, "auth.oauth_token_ttl: must be positive")
+	assert.ErrorContains(t, err, "auth.api_key_cache_ttl: must not exceed 5m0s")
+
+	t.Setenv("DB_MAX_IDLE_CONNS", "5")
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	_, err = config.LoadAppConfig([]string{"-log-level", "loud", "-log-format", "xml", "-error-format", "html", "-access-log-sample-rate", "2",
+		"-tls-client-ca-file", missing, "-client-cert-identities-file", missing, "-rate-limits-file", missing, "-usage-quotas-file", writeAppConfigFile(t, "quotas.json", "{")})
+	assert.ErrorContains(t, err, "log.level: must be debug, info, warn or error")
+	assert.ErrorContains(t, err, "log.format: must be json or text")
+	assert.ErrorContains(t, err, "errors.format: must be envelope or problem")
+	assert.ErrorContains(t, err, "log.access_log_sample_rate: must be between 0 and 1")
+	assert.ErrorContains(t, err, "tls.cert_file: must be set to verify client certificates")
+	assert.ErrorContains(t, err, "tls.client_cert_identities_file: open "+missing)
+	assert.ErrorContains(t, err, "limits.rate_limits_file: open "+missing)
+	assert.ErrorContains(t, err, "limits.usage_quotas_file: ")
+	assert.ErrorContains(t, err, "quotas.json: unexpected end of JSON input")
+}
+
+func TestAppConfigExampleFile(t *testing.T) {
+	appConfig, err := config.LoadAppConfig([]string{"-config", "../config.example.yaml"})
+	assert.Nil(t, err)
+	assert.Equal(t, config.DefaultAppConfig(), appConfig)
+}
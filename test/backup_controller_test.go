@@ -0,0 +1,115 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveGzipRoundTrip(t *testing.T) {
+	archive := web.BackupArchive{
+		SchemaVersion: 1,
+		CategoryCount: 1,
+		Categories:    []web.CategoryResponse{{Id: 7, Name: "Gadget"}},
+	}
+
+	buffer := &bytes.Buffer{}
+	helper.WriteArchive(buffer, archive, true)
+	assert.Equal(t, []byte{0x1f, 0x8b}, buffer.Bytes()[:2])
+
+	result := web.BackupArchive{}
+	err := helper.ReadArchive(buffer, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, archive.Categories, result.Categories)
+}
+
+func TestArchiveDecompressedSizeIsCapped(t *testing.T) {
+	defer func(max int64) { helper.MaxArchiveBytes = max }(helper.MaxArchiveBytes)
+	helper.MaxArchiveBytes = 1024
+
+	archive := web.BackupArchive{SchemaVersion: 1, Categories: []web.CategoryResponse{{Id: 7, Name: strings.Repeat("a", 4096)}}}
+	buffer := &bytes.Buffer{}
+	helper.WriteArchive(buffer, archive, true)
+	assert.Less(t, buffer.Len(), 1024)
+
+	err := helper.ReadArchive(buffer, &web.BackupArchive{})
+	assert.EqualError(t, err, "archive is larger than 1024 bytes once decompressed")
+}
+
+func TestBackupAndRestorePreservingIds(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	tx, _ := db.Begin()
+	categoryRepository := repository.NewCategoryRepository()
+	category := categoryRepository.Save(tenantContext(), tx, domain.Category{
+		Name: "Gadget",
+	})
+	tx.Commit()
+
+	router := setupRouter(db)
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/admin/backup?format=gzip", nil)
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "application/gzip", response.Header.Get("Content-Type"))
+	backup, _ := io.ReadAll(response.Body)
+
+	truncateCategory(db)
+
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/restore?preserve_ids=true&replace=true", bytes.NewReader(backup))
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder = httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response = recorder.Result()
+	assert.Equal(t, 200, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+	fmt.Println(responseBody)
+
+	assert.Equal(t, 1, int(responseBody["data"].(map[string]interface{})["restored"].(float64)))
+
+	tx, _ = db.Begin()
+	restored, err := categoryRepository.FindById(tenantContext(), tx, category.Id)
+	tx.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, "Gadget", restored.Name)
+}
+
+func TestRestoreRejectsUnknownSchemaVersion(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	router := setupRouter(db)
+	requestBody := bytes.NewReader([]byte(`{"schema_version": 99, "category_count": 0, "categories": []}`))
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/restore", requestBody)
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 400, response.StatusCode)
+}
@@ -13,7 +13,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/mrakhaf/golang-restful-api/config"
 	"github.com/mrakhaf/golang-restful-api/controller"
@@ -26,7 +25,7 @@ import (
 )
 
 func setupTestDB() *sql.DB {
-	db, err := sql.Open("mysql", "root:@tcp(localhost:3306)/belajar_golang_restful_api_test")
+	db, err := sql.Open("mysql", "root:@tcp(localhost:3306)/belajar_golang_restful_api_test?parseTime=true")
 	helper.PanicIfError(err)
 
 	db.SetMaxIdleConns(5)
@@ -38,18 +37,52 @@ func setupTestDB() *sql.DB {
 }
 
 func setupRouter(db *sql.DB) http.Handler {
-	validate := validator.New()
+	validate := helper.Validator()
 	categoryRepository := repository.NewCategoryRepository()
-	serviceCategory := service.NewCategoryService(categoryRepository, db, validate)
+	productRepository := repository.NewProductRepository()
+	serviceCategory := service.NewCategoryService(categoryRepository, productRepository, db, validate)
 	categoryController := controller.NewCategoryController(serviceCategory)
+	productService := service.NewProductService(productRepository, categoryRepository, db, validate)
+	productController := controller.NewProductController(productService)
+	backupService := service.NewBackupService(categoryRepository, productRepository, db, validate)
+	backupController := controller.NewBackupController(backupService)
+	apiKeyCache := middleware.NewApiKeyCache(time.Second, 100)
+	apiKeyService := service.NewApiKeyService(repository.NewApiKeyRepository(), db, validate, apiKeyCache)
+	apiKeyController := controller.NewApiKeyController(apiKeyService)
+
+	oauthService := service.NewOauthService(repository.NewOauthClientRepository(), repository.NewOauthTokenRepository(), db, validate, testOauthTokenConfig)
+	oauthController := controller.NewOauthController(oauthService)
+
+	usageService := service.NewUsageService(repository.NewUsageRepository(), db, testUsageQuota, nil)
+	usageController := controller.NewUsageController(usageService)
+
+	rateLimit := middleware.NewRateLimitMiddleware(config.DefaultRateLimits, nil, middleware.DefaultMaxBuckets)
+	clientIpRateLimit := middleware.NewRateLimitMiddleware(config.DefaultClientIpRateLimits, nil, middleware.DefaultMaxBuckets)
+	authMiddleware := middleware.NewAuthMiddleware(
+		middleware.NewApiKeyAuthenticator(map[string]string{
+			"rahasia":        config.DefaultTenant,
+			"rahasia-tenant": "tenant-b",
+		}, apiKeyService, apiKeyCache),
+		middleware.NewOauthAuthenticator(oauthService),
+		middleware.NewJwtAuthenticator(testJwtConfig()),
+	)
+
+	chains := config.RouteChains{
+		Public:    middleware.NewChain(rateLimit),
+		Protected: middleware.NewChain(clientIpRateLimit, authMiddleware, rateLimit),
+		Metered:   middleware.NewChain(middleware.NewUsageMiddleware(usageService)),
+	}
+
+	return config.NewRouter(chains, controller.NewHealthController(db), categoryController, productController, backupController, apiKeyController, oauthController, usageController)
+}
 
-	router := config.NewRouter(categoryController)
-
-	return middleware.NewAuthMiddleware(router)
+func tenantContext() context.Context {
+	return helper.WithTenant(context.Background(), config.DefaultTenant)
 }
 
 func truncateCategory(db *sql.DB) {
-	db.Exec("TRUNCATE category")
+	db.Exec("DELETE FROM product")
+	db.Exec("DELETE FROM category")
 }
 
 func TestCreateCategorySuccess(t *testing.T) {
@@ -81,6 +114,23 @@ func TestCreateCategorySuccess(t *testing.T) {
 
 }
 
+func TestCreateCategoryNameTaken(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	status, _ := serve(router, http.MethodPost, "http://localhost:3000/api/categories", "rahasia", `{"name": "Gadget"}`)
+	assert.Equal(t, 200, status)
+
+	status, body := serve(router, http.MethodPost, "http://localhost:3000/api/categories", "rahasia", `{"name": "Gadget"}`)
+	assert.Equal(t, 409, status)
+	assert.Equal(t, "CATEGORY_NAME_TAKEN", body["error_code"])
+
+	// names are unique per tenant only
+	status, _ = serve(router, http.MethodPost, "http://localhost:3000/api/categories", "rahasia-tenant", `{"name": "Gadget"}`)
+	assert.Equal(t, 200, status)
+}
+
 func TestCreateCategoryFailed(t *testing.T) {
 	db := setupTestDB()
 	truncateCategory(db)
@@ -112,7 +162,7 @@ func TestUpdateCategorySucces(t *testing.T) {
 
 	tx, _ := db.Begin()
 	categoryRepository := repository.NewCategoryRepository()
-	category := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category := categoryRepository.Save(tenantContext(), tx, domain.Category{
 		Name: "Gadget1",
 	})
 	tx.Commit()
@@ -147,7 +197,7 @@ func TestUpdateCategoryFailed(t *testing.T) {
 
 	tx, _ := db.Begin()
 	categoryRepository := repository.NewCategoryRepository()
-	category := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category := categoryRepository.Save(tenantContext(), tx, domain.Category{
 		Name: "Gadget1",
 	})
 	tx.Commit()
@@ -180,7 +230,7 @@ func TestGetCategorySuccess(t *testing.T) {
 
 	tx, _ := db.Begin()
 	categoryRepository := repository.NewCategoryRepository()
-	category := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category := categoryRepository.Save(tenantContext(), tx, domain.Category{
 		Name: "Gadget1",
 	})
 	tx.Commit()
@@ -239,7 +289,7 @@ func TestDeleteCategorySuccess(t *testing.T) {
 
 	tx, _ := db.Begin()
 	categoryRepository := repository.NewCategoryRepository()
-	category := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category := categoryRepository.Save(tenantContext(), tx, domain.Category{
 		Name: "Gadget1",
 	})
 	tx.Commit()
@@ -294,10 +344,10 @@ func TestListCategoriesSuccess(t *testing.T) {
 
 	tx, _ := db.Begin()
 	categoryRepository := repository.NewCategoryRepository()
-	category := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category := categoryRepository.Save(tenantContext(), tx, domain.Category{
 		Name: "Gadget1",
 	})
-	category2 := categoryRepository.Save(context.Background(), tx, domain.Category{
+	category2 := categoryRepository.Save(tenantContext(), tx, domain.Category{
 		Name: "Gadget2",
 	})
 	tx.Commit()
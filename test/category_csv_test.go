@@ -0,0 +1,192 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+	"github.com/mrakhaf/golang-restful-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportCategoriesCsv(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	tx, _ := db.Begin()
+	categoryRepository := repository.NewCategoryRepository()
+	category := categoryRepository.Save(tenantContext(), tx, domain.Category{
+		Name: "Gadget, Phone",
+	})
+	tx.Commit()
+
+	router := setupRouter(db)
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/export?format=csv", nil)
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "text/csv", response.Header.Get("Content-Type"))
+
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, fmt.Sprintf("id,name\n%d,\"Gadget, Phone\"\n", category.Id), string(body))
+}
+
+func TestExportCategoriesCsvEscapesFormulas(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	tx, _ := db.Begin()
+	categoryRepository := repository.NewCategoryRepository()
+	formula := categoryRepository.Save(tenantContext(), tx, domain.Category{Name: "=HYPERLINK(\"http://evil\")"})
+	mention := categoryRepository.Save(tenantContext(), tx, domain.Category{Name: "@Gadget"})
+	plain := categoryRepository.Save(tenantContext(), tx, domain.Category{Name: "Gadget-Phone"})
+	tx.Commit()
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/export", nil)
+	request.Header.Add("X-API-Key", "rahasia")
+	recorder := httptest.NewRecorder()
+	setupRouter(db).ServeHTTP(recorder, request)
+
+	assert.Equal(t, 200, recorder.Code)
+	expected := fmt.Sprintf("id,name\n%d,\"'=HYPERLINK(\"\"http://evil\"\")\"\n%d,'@Gadget\n%d,Gadget-Phone\n", formula.Id, mention.Id, plain.Id)
+	assert.Equal(t, expected, recorder.Body.String())
+}
+
+func TestImportCategoriesCsvReportsLines(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	router := setupRouter(db)
+	requestBody := strings.NewReader("name\nGadget\n\"\"\nFood\n")
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/import", requestBody)
+	request.Header.Add("Content-Type", "text/csv")
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 200, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+	fmt.Println(responseBody)
+
+	data := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, 3, int(data["total"].(float64)))
+	assert.Equal(t, 2, int(data["created"].(float64)))
+	assert.Equal(t, 1, int(data["failed"].(float64)))
+
+	failed := data["lines"].([]interface{})[1].(map[string]interface{})
+	assert.Equal(t, 3, int(failed["line"].(float64)))
+	assert.Equal(t, "failed", failed["action"])
+	assert.Equal(t, "validation failed", failed["error"])
+	validationError := failed["errors"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "name", validationError["field"])
+	assert.Equal(t, "required", validationError["rule"])
+	assert.Equal(t, "name is a required field", validationError["message"])
+}
+
+func TestImportCategoriesCsvUnescapesFormulas(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/import", strings.NewReader("name\n'=SUM(A1)\n'Quoted\n"))
+	request.Header.Add("Content-Type", "text/csv")
+	request.Header.Add("X-API-Key", "rahasia")
+	recorder := httptest.NewRecorder()
+	setupRouter(db).ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+
+	tx, _ := db.Begin()
+	defer tx.Commit()
+	names := []string{}
+	for _, category := range repository.NewCategoryRepository().FindAll(tenantContext(), tx) {
+		names = append(names, category.Name)
+	}
+	assert.ElementsMatch(t, []string{"=SUM(A1)", "'Quoted"}, names)
+}
+
+func TestImportCategoriesCsvDryRunUpsert(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	tx, _ := db.Begin()
+	categoryRepository := repository.NewCategoryRepository()
+	categoryRepository.Save(tenantContext(), tx, domain.Category{
+		Name: "Gadget",
+	})
+	tx.Commit()
+
+	requestBody := &bytes.Buffer{}
+	form := multipart.NewWriter(requestBody)
+	file, _ := form.CreateFormFile("file", "categories.csv")
+	file.Write([]byte("id,name\n1,Gadget\n2,Food\n"))
+	form.Close()
+
+	router := setupRouter(db)
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/import?dry_run=true&mode=upsert", requestBody)
+	request.Header.Add("Content-Type", form.FormDataContentType())
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 200, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+	fmt.Println(responseBody)
+
+	data := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, true, data["dry_run"])
+	assert.Equal(t, 1, int(data["updated"].(float64)))
+	assert.Equal(t, 1, int(data["created"].(float64)))
+
+	tx, _ = db.Begin()
+	categories := categoryRepository.FindAll(tenantContext(), tx)
+	tx.Commit()
+	assert.Equal(t, 1, len(categories))
+}
+
+func TestImportCategoriesCsvWithoutNameColumn(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	router := setupRouter(db)
+	requestBody := strings.NewReader("title\nGadget\n")
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/import", requestBody)
+	request.Header.Add("Content-Type", "text/csv")
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 400, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+
+	assert.Equal(t, 400, int(responseBody["code"].(float64)))
+	assert.Equal(t, "BAD REQUEST", responseBody["status"])
+}
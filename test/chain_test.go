@@ -0,0 +1,82 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// tracing appends name to the X-Trace response header when a request passes through it.
+func tracing(name string) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Add("X-Trace", name)
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	chain := middleware.NewChain(tracing("first"), tracing("second"))
+	extended := chain.Append(tracing("third"))
+
+	recorder := httptest.NewRecorder()
+	extended.Then(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {})).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, []string{"first", "second", "third"}, recorder.Header().Values("X-Trace"))
+	assert.Equal(t, 2, len(chain))
+}
+
+func TestPublicAndProtectedRoutes(t *testing.T) {
+	authMiddleware := middleware.NewAuthMiddleware(middleware.NewApiKeyAuthenticator(map[string]string{"rahasia": config.DefaultTenant}, nil, nil))
+	echoParam := func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		writer.Write([]byte(params.ByName("categoryId")))
+	}
+
+	router := httprouter.New()
+	router.PanicHandler = exeption.ErrorHandler
+	public := config.Routes{Router: router, Chain: middleware.NewChain(tracing("public"))}
+	protected := config.Routes{Router: router, Chain: middleware.NewChain(authMiddleware)}
+
+	public.GET("/health", echoParam)
+	protected.GET("/api/categories/:categoryId", echoParam)
+	protected.With(tracing("own")).DELETE("/api/categories/:categoryId", echoParam)
+
+	serveRoute := func(method string, target string, apiKey string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, nil)
+		request.Header.Add("X-API-Key", apiKey)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := serveRoute(http.MethodGet, "http://localhost:3000/health", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "public", recorder.Header().Get("X-Trace"))
+
+	assert.Equal(t, 401, serveRoute(http.MethodGet, "http://localhost:3000/api/categories/7", "").Code)
+
+	recorder = serveRoute(http.MethodGet, "http://localhost:3000/api/categories/7", "rahasia")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "7", recorder.Body.String())
+	assert.Equal(t, "", recorder.Header().Get("X-Trace"))
+
+	assert.Equal(t, 401, serveRoute(http.MethodDelete, "http://localhost:3000/api/categories/7", "").Code)
+	recorder = serveRoute(http.MethodDelete, "http://localhost:3000/api/categories/7", "rahasia")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "own", recorder.Header().Get("X-Trace"))
+}
+
+func TestHealthIsPublic(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/health", nil)
+	recorder := httptest.NewRecorder()
+	setupRouter(setupTestDB()).ServeHTTP(recorder, request)
+
+	assert.Equal(t, 200, recorder.Code)
+	assert.JSONEq(t, `{"code": 200, "status": "OK", "data": "UP"}`, recorder.Body.String())
+}
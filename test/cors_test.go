@@ -0,0 +1,113 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCorsHandler(corsConfig middleware.CorsConfig) http.Handler {
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte("OK"))
+	})
+	return middleware.NewChain(middleware.NewCorsMiddleware(corsConfig), middleware.NewAuthMiddleware(middleware.NewApiKeyAuthenticator(map[string]string{"rahasia": config.DefaultTenant}, nil, nil))).Then(handler)
+}
+
+func testCorsConfig() middleware.CorsConfig {
+	return middleware.CorsConfig{
+		AllowedOrigins: []string{"https://admin.example.com", "https://*.example.org"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+		AllowedHeaders: []string{"Content-Type", "X-API-Key"},
+		ExposedHeaders: []string{"Retry-After"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+func preflight(handler http.Handler, origin string, method string, headers string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodOptions, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("Origin", origin)
+	request.Header.Add("Access-Control-Request-Method", method)
+	request.Header.Add("Access-Control-Request-Headers", headers)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestCorsPreflightBeforeAuth(t *testing.T) {
+	recorder := preflight(setupCorsHandler(testCorsConfig()), "https://admin.example.com", "POST", "content-type, x-api-key")
+
+	assert.Equal(t, 204, recorder.Code)
+	assert.Equal(t, "https://admin.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST, PUT, DELETE", recorder.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, X-API-Key", recorder.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", recorder.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCorsPreflightRefused(t *testing.T) {
+	handler := setupCorsHandler(testCorsConfig())
+
+	for _, recorder := range []*httptest.ResponseRecorder{
+		preflight(handler, "https://evil.example.com", "GET", ""),
+		preflight(handler, "https://example.org", "GET", ""),
+		preflight(handler, "https://admin.example.com", "PATCH", ""),
+		preflight(handler, "https://admin.example.com", "GET", "X-Custom"),
+	} {
+		assert.Equal(t, 204, recorder.Code)
+		assert.Equal(t, "", recorder.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestCorsWildcardSubdomain(t *testing.T) {
+	handler := setupCorsHandler(testCorsConfig())
+
+	recorder := preflight(handler, "https://shop.eu.example.org", "DELETE", "")
+	assert.Equal(t, "https://shop.eu.example.org", recorder.Header().Get("Access-Control-Allow-Origin"))
+
+	recorder = preflight(handler, "https://evil.com/.example.org", "DELETE", "")
+	assert.Equal(t, "", recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCorsActualRequest(t *testing.T) {
+	corsConfig := testCorsConfig()
+	corsConfig.AllowCredentials = true
+	handler := setupCorsHandler(corsConfig)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("Origin", "https://admin.example.com")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	// the headers are also sent on errors, so the browser lets the app read the 401
+	assert.Equal(t, 401, recorder.Code)
+	assert.Equal(t, "https://admin.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Retry-After", recorder.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "Origin", recorder.Header().Get("Vary"))
+
+	request.Header.Add("X-API-Key", "rahasia")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 200, recorder.Code)
+}
+
+func TestCorsConfigRefusesCredentialsForAnyOrigin(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://admin.example.com, *, admin.example.com")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	_, err := config.LoadAppConfig(nil)
+	assert.ErrorContains(t, err, "cors.allow_credentials: cannot be combined with the * origin, list the trusted origins in cors.allowed_origins")
+	assert.ErrorContains(t, err, `cors.allowed_origins: "admin.example.com" is not an origin like https://admin.example.com`)
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://admin.example.com, https://*.example.com")
+	appConfig, err := config.LoadAppConfig(nil)
+	assert.Nil(t, err)
+	corsConfig, ok := config.NewCorsConfig(appConfig.Cors)
+	assert.True(t, ok)
+	assert.True(t, corsConfig.AllowCredentials)
+	assert.Equal(t, []string{"https://admin.example.com", "https://*.example.com"}, corsConfig.AllowedOrigins)
+	assert.Equal(t, []string{"GET", "POST", "PUT", "DELETE"}, corsConfig.AllowedMethods)
+}
@@ -0,0 +1,17 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCsvCellRoundTrip(t *testing.T) {
+	for _, value := range []string{"Gadget", "=SUM(A1)", "+62", "-name", "@Gadget", "\tTab", "'Quoted", "'=kept", ""} {
+		assert.Equal(t, value, helper.UnescapeCsvCell(helper.EscapeCsvCell(value)), value)
+	}
+	assert.Equal(t, "'-name", helper.EscapeCsvCell("-name"))
+	assert.Equal(t, "'Quoted", helper.EscapeCsvCell("'Quoted"))
+	assert.Equal(t, "''=kept", helper.EscapeCsvCell("'=kept"))
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodeInEnvelope(t *testing.T) {
+	status, body := serveDecoding(http.MethodGet, "/api/categories/abc", "", nil)
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "PATH_PARAM_INVALID", body["error_code"])
+
+	status, body = serveDecoding(http.MethodPost, "/api/categories", "text/plain", strings.NewReader(`{"name":"Gadget"}`))
+	assert.Equal(t, 415, status)
+	assert.Equal(t, "CONTENT_TYPE_UNSUPPORTED", body["error_code"])
+	assert.Equal(t, "Content-Type must be application/json", body["data"])
+
+	status, body = serveDecoding(http.MethodPost, "/api/categories", "application/json", strings.NewReader(`{"name":`))
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "REQUEST_BODY_INVALID", body["error_code"])
+
+	recorder, body := serveProblem(http.MethodGet, "/api/categories/7", "application/json", "")
+	assert.Equal(t, 401, recorder.Code)
+	assert.Equal(t, "UNAUTHORIZED", body["error_code"])
+
+	recorder, body = serveProblem(http.MethodGet, "/api/categories/7", "application/json", "rahasia")
+	assert.Equal(t, 404, recorder.Code)
+	assert.Equal(t, "NOT_FOUND", body["error_code"])
+}
+
+func TestErrorCodeOfUnknownRoutes(t *testing.T) {
+	router := setupRouter(setupTestDB())
+
+	status, body := serve(router, http.MethodGet, "http://localhost:3000/api/unknown", "rahasia", "")
+	assert.Equal(t, 404, status)
+	assert.Equal(t, "NOT_FOUND", body["error_code"])
+
+	request := httptest.NewRequest(http.MethodPatch, "http://localhost:3000/api/categories", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	assert.Equal(t, 405, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Allow"), http.MethodGet)
+	assert.Contains(t, recorder.Body.String(), `"error_code":"METHOD_NOT_ALLOWED"`)
+}
+
+func TestErrorCodeInProblem(t *testing.T) {
+	recorder, body := serveProblem(http.MethodDelete, "/api/categories/7", "application/problem+json", "rahasia")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Equal(t, "INTERNAL_ERROR", body["error_code"])
+
+	recorder, body = serveProblem(http.MethodPost, "/api/categories", "application/problem+json", "rahasia")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, "VALIDATION_FAILED", body["error_code"])
+}
+
+func TestErrorCodeCatalogIsUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, code := range exeption.ErrorCodes {
+		assert.False(t, seen[code.Code], code.Code)
+		assert.NotZero(t, code.Status, code.Code)
+		assert.NotEmpty(t, code.Message, code.Code)
+		seen[code.Code] = true
+	}
+	assert.True(t, seen[exeption.CategoryNotFound.Code])
+}
+
+func TestErrorCodeCatalogIsGenerated(t *testing.T) {
+	docs, err := os.ReadFile("../docs/error-codes.md")
+	assert.Nil(t, err)
+	spec, err := os.ReadFile("../apispec.json")
+	assert.Nil(t, err)
+
+	parsed := struct {
+		Components struct {
+			Schemas struct {
+				ErrorCode struct {
+					Enum []string `json:"enum"`
+				} `json:"ErrorCode"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}{}
+	assert.Nil(t, json.Unmarshal(spec, &parsed))
+
+	codes := []string{}
+	for _, code := range exeption.ErrorCodes {
+		codes = append(codes, code.Code)
+		assert.Contains(t, string(docs), "| `"+code.Code+"` |", "run go generate ./exeption")
+	}
+	assert.Equal(t, codes, parsed.Components.Schemas.ErrorCode.Enum, "run go generate ./exeption")
+}
@@ -0,0 +1,190 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/mrakhaf/golang-restful-api/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+var hmacSecret = []byte("rahasia-hmac")
+
+// setupHmacHandler echoes the tenant and body the handler sees behind the auth middleware.
+func setupHmacHandler() http.Handler {
+	authMiddleware := middleware.NewAuthMiddleware(middleware.NewHmacAuthenticator(map[string]middleware.HmacKey{
+		"service-a": {Secret: hmacSecret, TenantId: "tenant-b", Scopes: []string{"categories:read"}},
+	}, time.Minute, 100))
+	return authMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		writer.Write([]byte(helper.TenantFromContext(request.Context()) + " " + string(body)))
+	}))
+}
+
+func signedRequest(method string, url string, body string) *http.Request {
+	request := httptest.NewRequest(method, url, strings.NewReader(body))
+	helper.PanicIfError(signing.Sign(request, "service-a", hmacSecret))
+	return request
+}
+
+func serveSigned(handler http.Handler, request *http.Request) (int, string) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Body.String()
+}
+
+func TestHmacSignedRequest(t *testing.T) {
+	status, body := serveSigned(setupHmacHandler(), signedRequest(http.MethodPost, "http://localhost:3000/api/categories?b=2&a=1", `{"name": "Gadget"}`))
+	assert.Equal(t, 200, status)
+	assert.Equal(t, `tenant-b {"name": "Gadget"}`, body)
+}
+
+func TestHmacTamperedRequestUnauthorized(t *testing.T) {
+	handler := setupHmacHandler()
+
+	request := signedRequest(http.MethodPost, "http://localhost:3000/api/categories", `{"name": "Gadget"}`)
+	request.Body = io.NopCloser(strings.NewReader(`{"name": "Laptop"}`))
+	status, _ := serveSigned(handler, request)
+	assert.Equal(t, 401, status)
+
+	request = signedRequest(http.MethodGet, "http://localhost:3000/api/categories?page=1", "")
+	request.URL.RawQuery = "page=2"
+	status, _ = serveSigned(handler, request)
+	assert.Equal(t, 401, status)
+
+	request = signedRequest(http.MethodGet, "http://localhost:3000/api/categories", "")
+	request.Method = http.MethodDelete
+	status, _ = serveSigned(handler, request)
+	assert.Equal(t, 401, status)
+
+	request = signedRequest(http.MethodGet, "http://localhost:3000/api/categories", "")
+	request.Header.Set(signing.HeaderKeyId, "service-b")
+	status, _ = serveSigned(handler, request)
+	assert.Equal(t, 401, status)
+}
+
+func TestHmacTimestampOutsideWindowUnauthorized(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	timestamp := strconv.FormatInt(time.Now().Add(-2*time.Minute).Unix(), 10)
+	request.Header.Set(signing.HeaderKeyId, "service-a")
+	request.Header.Set(signing.HeaderTimestamp, timestamp)
+	request.Header.Set(signing.HeaderNonce, "nonce")
+	request.Header.Set(signing.HeaderSignature, signing.Signature(hmacSecret,
+		signing.StringToSign(http.MethodGet, "/api/categories", nil, signing.BodyHash(nil), timestamp, "nonce")))
+
+	status, _ := serveSigned(setupHmacHandler(), request)
+	assert.Equal(t, 401, status)
+}
+
+func TestHmacReplayedNonceUnauthorized(t *testing.T) {
+	handler := setupHmacHandler()
+	request := signedRequest(http.MethodGet, "http://localhost:3000/api/categories", "")
+	replay := request.Clone(request.Context())
+
+	status, _ := serveSigned(handler, request)
+	assert.Equal(t, 200, status)
+
+	status, _ = serveSigned(handler, replay)
+	assert.Equal(t, 401, status)
+}
+
+func TestNonceStoreIsBounded(t *testing.T) {
+	store := middleware.NewNonceStore(20*time.Millisecond, 2, 2)
+	assert.Nil(t, store.Add("service-a", "a"))
+	assert.Equal(t, middleware.ErrNonceUsed, store.Add("service-a", "a"))
+	assert.Nil(t, store.Add("service-b", "a"))
+	assert.ErrorIs(t, store.Add("service-a", "c"), middleware.ErrNonceStoreFull)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Nil(t, store.Add("service-a", "c"))
+	assert.Nil(t, store.Add("service-a", "a"))
+}
+
+func TestNonceStoreIsBoundedPerKey(t *testing.T) {
+	store := middleware.NewNonceStore(time.Minute, 3, 2)
+	assert.Nil(t, store.Add("service-a", "a"))
+	assert.Nil(t, store.Add("service-a", "b"))
+
+	err := store.Add("service-a", "c")
+	assert.ErrorIs(t, err, middleware.ErrNonceStoreFull)
+	var full *middleware.NonceStoreFullError
+	assert.ErrorAs(t, err, &full)
+	assert.InDelta(t, time.Minute.Seconds(), full.RetryAfter.Seconds(), 1)
+
+	assert.Nil(t, store.Add("service-b", "c"))
+}
+
+func TestHmacFullNonceStoreAsksToRetry(t *testing.T) {
+	handler := middleware.NewAuthMiddleware(middleware.NewHmacAuthenticator(map[string]middleware.HmacKey{
+		"service-a": {Secret: hmacSecret, TenantId: "tenant-b"},
+		"service-b": {Secret: hmacSecret, TenantId: "tenant-b"},
+	}, time.Minute, 2))(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+
+	status, _ := serveSigned(handler, signedRequest(http.MethodGet, "http://localhost:3000/api/categories", ""))
+	assert.Equal(t, 200, status)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, signedRequest(http.MethodGet, "http://localhost:3000/api/categories", ""))
+	assert.Equal(t, 429, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "RATE_LIMITED")
+	assert.Equal(t, "120", recorder.Header().Get("Retry-After"))
+
+	// service-a used up its share, service-b still has its own
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	helper.PanicIfError(signing.Sign(request, "service-b", hmacSecret))
+	status, _ = serveSigned(handler, request)
+	assert.Equal(t, 200, status)
+}
+
+func TestHmacSignSetsGetBody(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories", io.NopCloser(strings.NewReader(`{"name": "Gadget"}`)))
+	assert.Nil(t, signing.Sign(request, "service-a", hmacSecret))
+	assert.Equal(t, int64(18), request.ContentLength)
+
+	for i := 0; i < 2; i++ {
+		body, err := request.GetBody()
+		assert.Nil(t, err)
+		content, _ := io.ReadAll(body)
+		assert.Equal(t, `{"name": "Gadget"}`, string(content))
+	}
+}
+
+func TestHmacSigningTransportFollowsRedirects(t *testing.T) {
+	redirecting := http.NewServeMux()
+	redirecting.Handle("/api/categories", setupHmacHandler())
+	redirecting.HandleFunc("/old/categories", func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, "/api/categories", http.StatusPermanentRedirect)
+	})
+	server := httptest.NewServer(redirecting)
+	defer server.Close()
+
+	client := &http.Client{Transport: signing.NewTransport(nil, "service-a", hmacSecret)}
+	response, err := client.Post(server.URL+"/old/categories", "application/json", strings.NewReader(`{"name": "Gadget"}`))
+	helper.PanicIfError(err)
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `tenant-b {"name": "Gadget"}`, string(body))
+}
+
+func TestHmacSigningTransport(t *testing.T) {
+	server := httptest.NewServer(setupHmacHandler())
+	defer server.Close()
+
+	client := &http.Client{Transport: signing.NewTransport(nil, "service-a", hmacSecret)}
+	response, err := client.Post(server.URL+"/api/categories", "application/json", strings.NewReader(`{"name": "Gadget"}`))
+	helper.PanicIfError(err)
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `tenant-b {"name": "Gadget"}`, string(body))
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	jwtSecret      = []byte("rahasia-jwt")
+	jwtRsaKey, _   = rsa.GenerateKey(rand.Reader, 2048)
+	jwtEcdsaKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+)
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func testJwks() []byte {
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kid": "rsa", "kty": "RSA", "use": "sig", "n": encodeBigInt(jwtRsaKey.N), "e": encodeBigInt(big.NewInt(int64(jwtRsaKey.E)))},
+			{"kid": "ec", "kty": "EC", "crv": "P-256", "x": encodeBigInt(jwtEcdsaKey.X), "y": encodeBigInt(jwtEcdsaKey.Y)},
+		},
+	})
+	return jwks
+}
+
+func testJwtConfig() middleware.JwtConfig {
+	keys, err := middleware.ParseJwks(testJwks())
+	helper.PanicIfError(err)
+
+	return middleware.JwtConfig{
+		Secret:    jwtSecret,
+		Keys:      keys,
+		Issuer:    "https://gateway.test",
+		Audience:  "golang-restful-api",
+		ClockSkew: 30 * time.Second,
+	}
+}
+
+func jwtClaims(overrides jwt.MapClaims) jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":    "service-a",
+		"tenant": config.DefaultTenant,
+		"iss":    "https://gateway.test",
+		"aud":    "golang-restful-api",
+		"scope":  "categories:read categories:write",
+		"iat":    now.Unix(),
+		"exp":    now.Add(time.Minute).Unix(),
+	}
+	for name, value := range overrides {
+		claims[name] = value
+	}
+	return claims
+}
+
+func signJwt(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	helper.PanicIfError(err)
+	return signed
+}
+
+// serveBearer runs the auth middleware in front of a handler that echoes the principal.
+func serveBearer(token string) (int, string, map[string]interface{}) {
+	var tenantId string
+	var claims map[string]interface{}
+	handler := middleware.NewAuthMiddleware(middleware.NewJwtAuthenticator(testJwtConfig()))(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		principal, _ := helper.PrincipalFromContext(request.Context())
+		tenantId = helper.TenantFromContext(request.Context())
+		claims = principal.Claims
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	return recorder.Code, tenantId, claims
+}
+
+func TestJwtSignedWithSecret(t *testing.T) {
+	status, tenantId, claims := serveBearer(signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(nil)))
+	assert.Equal(t, 200, status)
+	assert.Equal(t, config.DefaultTenant, tenantId)
+	assert.Equal(t, "service-a", claims["sub"])
+}
+
+func TestJwtSignedWithJwksKeys(t *testing.T) {
+	status, _, _ := serveBearer(signJwt(jwt.SigningMethodRS256, "rsa", jwtRsaKey, jwtClaims(nil)))
+	assert.Equal(t, 200, status)
+
+	status, _, _ = serveBearer(signJwt(jwt.SigningMethodES256, "ec", jwtEcdsaKey, jwtClaims(nil)))
+	assert.Equal(t, 200, status)
+
+	status, _, _ = serveBearer(signJwt(jwt.SigningMethodRS256, "unknown", jwtRsaKey, jwtClaims(nil)))
+	assert.Equal(t, 401, status)
+}
+
+func TestJwtRejected(t *testing.T) {
+	now := time.Now()
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tokens := map[string]string{
+		"wrong secret":   signJwt(jwt.SigningMethodHS256, "", []byte("salah"), jwtClaims(nil)),
+		"wrong key":      signJwt(jwt.SigningMethodRS256, "rsa", otherKey, jwtClaims(nil)),
+		"expired":        signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()})),
+		"not yet valid":  signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(jwt.MapClaims{"nbf": now.Add(time.Minute).Unix()})),
+		"without exp":    signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(jwt.MapClaims{"exp": nil})),
+		"wrong issuer":   signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(jwt.MapClaims{"iss": "https://other.test"})),
+		"wrong audience": signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(jwt.MapClaims{"aud": "other"})),
+		"without tenant": signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(jwt.MapClaims{"tenant": nil})),
+		"unsigned":       signJwt(jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, jwtClaims(nil)),
+		"malformed":      "not-a-jwt",
+	}
+
+	for name, token := range tokens {
+		status, _, _ := serveBearer(token)
+		assert.Equal(t, 401, status, name)
+	}
+}
+
+func TestJwtClockSkew(t *testing.T) {
+	now := time.Now()
+
+	status, _, _ := serveBearer(signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()})))
+	assert.Equal(t, 200, status)
+
+	status, _, _ = serveBearer(signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(jwt.MapClaims{"nbf": now.Add(10 * time.Second).Unix()})))
+	assert.Equal(t, 200, status)
+}
+
+func TestJwtListCategories(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+	router := setupRouter(db)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(nil))))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, 200, recorder.Code)
+}
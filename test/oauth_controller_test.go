@@ -0,0 +1,128 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+var testOauthTokenConfig = service.OauthTokenConfig{
+	SigningKey: []byte("rahasia-oauth"),
+	Issuer:     config.OauthIssuer,
+	TTL:        time.Minute,
+}
+
+func truncateOauth() {
+	db := setupTestDB()
+	db.Exec("DELETE FROM oauth_revoked_token")
+	db.Exec("DELETE FROM oauth_client")
+}
+
+func registerOauthClient(t *testing.T, scopes string) (int, string, string) {
+	status, body := serve(setupRouter(setupTestDB()), http.MethodPost, "http://localhost:3000/api/admin/oauth-clients", "rahasia", `{"name": "partner", "scopes": `+scopes+`}`)
+	assert.Equal(t, 200, status)
+
+	data := body["data"].(map[string]interface{})
+	return int(data["id"].(float64)), data["client_id"].(string), data["client_secret"].(string)
+}
+
+func serveOauth(path string, clientId string, clientSecret string, form url.Values) (int, map[string]interface{}) {
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/oauth/"+path, strings.NewReader(form.Encode()))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(clientId, clientSecret)
+
+	recorder := httptest.NewRecorder()
+	setupRouter(setupTestDB()).ServeHTTP(recorder, request)
+
+	var body map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &body)
+	return recorder.Code, body
+}
+
+func serveWithBearer(method string, target string, token string) int {
+	request := httptest.NewRequest(method, target, nil)
+	request.Header.Add("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	setupRouter(setupTestDB()).ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+func TestOauthClientCredentials(t *testing.T) {
+	truncateOauth()
+	_, clientId, clientSecret := registerOauthClient(t, `["categories:read"]`)
+
+	status, body := serveOauth("token", clientId, clientSecret, url.Values{"grant_type": {"client_credentials"}})
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "Bearer", body["token_type"])
+	assert.Equal(t, 60, int(body["expires_in"].(float64)))
+	assert.Equal(t, "categories:read", body["scope"])
+	token := body["access_token"].(string)
+
+	assert.Equal(t, 200, serveWithBearer(http.MethodGet, "http://localhost:3000/api/categories", token))
+	assert.Equal(t, 403, serveWithBearer(http.MethodDelete, "http://localhost:3000/api/categories/1", token))
+}
+
+func TestOauthTokenRejected(t *testing.T) {
+	truncateOauth()
+	_, clientId, clientSecret := registerOauthClient(t, `["categories:read"]`)
+
+	status, body := serveOauth("token", clientId, "salah", url.Values{"grant_type": {"client_credentials"}})
+	assert.Equal(t, 401, status)
+	assert.Equal(t, "invalid_client", body["error"])
+	assert.Equal(t, "OAUTH_INVALID_CLIENT", body["error_code"])
+
+	status, body = serveOauth("token", clientId, clientSecret, url.Values{"grant_type": {"password"}})
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "unsupported_grant_type", body["error"])
+
+	status, body = serveOauth("token", clientId, clientSecret, url.Values{"grant_type": {"client_credentials"}, "scope": {"categories:write"}})
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "invalid_scope", body["error"])
+}
+
+func TestOauthIntrospectAndRevoke(t *testing.T) {
+	truncateOauth()
+	_, clientId, clientSecret := registerOauthClient(t, `["categories:read", "categories:write"]`)
+
+	_, body := serveOauth("token", clientId, clientSecret, url.Values{"grant_type": {"client_credentials"}, "scope": {"categories:read"}})
+	token := body["access_token"].(string)
+
+	status, body := serveOauth("introspect", clientId, clientSecret, url.Values{"token": {token}})
+	assert.Equal(t, 200, status)
+	assert.Equal(t, true, body["active"])
+	assert.Equal(t, "categories:read", body["scope"])
+	assert.Equal(t, clientId, body["client_id"])
+	assert.Equal(t, config.DefaultTenant, body["tenant"])
+
+	status, _ = serveOauth("revoke", clientId, clientSecret, url.Values{"token": {token}})
+	assert.Equal(t, 200, status)
+
+	_, body = serveOauth("introspect", clientId, clientSecret, url.Values{"token": {token}})
+	assert.Equal(t, map[string]interface{}{"active": false}, body)
+	assert.Equal(t, 401, serveWithBearer(http.MethodGet, "http://localhost:3000/api/categories", token))
+}
+
+func TestOauthRevokedClientTokensRejected(t *testing.T) {
+	truncateOauth()
+	id, clientId, clientSecret := registerOauthClient(t, `["categories:read"]`)
+
+	_, body := serveOauth("token", clientId, clientSecret, url.Values{"grant_type": {"client_credentials"}})
+	token := body["access_token"].(string)
+
+	status, _ := serve(setupRouter(setupTestDB()), http.MethodPost, "http://localhost:3000/api/admin/oauth-clients/"+strconv.Itoa(id)+"/revoke", "rahasia", "")
+	assert.Equal(t, 200, status)
+
+	assert.Equal(t, 401, serveWithBearer(http.MethodGet, "http://localhost:3000/api/categories", token))
+	status, _ = serveOauth("token", clientId, clientSecret, url.Values{"grant_type": {"client_credentials"}})
+	assert.Equal(t, 401, status)
+}
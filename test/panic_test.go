@@ -0,0 +1,83 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+const leakedError = "Error 1054: Unknown column 'secret_hash' in 'field list'"
+
+func setupPanicHandler() http.Handler {
+	router := httprouter.New()
+	router.PanicHandler = exeption.ErrorHandler
+	router.GET("/crash", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		panic(errors.New(leakedError))
+	})
+	crashing := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.URL.Path == "/middleware" {
+				panic(leakedError)
+			}
+			next.ServeHTTP(writer, request)
+		})
+	}
+	return middleware.NewChain(middleware.NewRequestIdMiddleware(), middleware.NewRecoverMiddleware()).Then(crashing(router))
+}
+
+func servePanic(t *testing.T, target string) (map[string]interface{}, string) {
+	output := &bytes.Buffer{}
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(output, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000"+target, nil)
+	request.Header.Add("X-Request-ID", "trace-1")
+	recorder := httptest.NewRecorder()
+	setupPanicHandler().ServeHTTP(recorder, request)
+	assert.Equal(t, 500, recorder.Code)
+
+	var body map[string]interface{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	return body, output.String()
+}
+
+func TestPanicHidesInternals(t *testing.T) {
+	for _, target := range []string{"/crash", "/middleware"} {
+		body, logged := servePanic(t, target)
+
+		data := body["data"].(map[string]interface{})
+		assert.Equal(t, "internal server error", data["message"])
+		assert.NotEmpty(t, data["reference"])
+		assert.NotEqual(t, "trace-1", data["reference"])
+		assert.NotContains(t, data, "error")
+		assert.NotContains(t, data, "stack")
+
+		records := logRecords(bytes.NewBufferString(logged))
+		assert.Len(t, records, 1)
+		assert.Equal(t, "trace-1", records[0]["request_id"])
+		assert.Equal(t, data["reference"], records[0]["reference"])
+		assert.Equal(t, leakedError, records[0]["error"])
+		assert.True(t, strings.Contains(records[0]["stack"].(string), "panic_test.go"))
+	}
+}
+
+func TestPanicDebugBody(t *testing.T) {
+	exeption.Debug = true
+	defer func() { exeption.Debug = false }()
+
+	body, _ := servePanic(t, "/crash")
+	data := body["data"].(map[string]interface{})
+	assert.Equal(t, leakedError, data["error"])
+	assert.NotEmpty(t, data["stack"])
+}
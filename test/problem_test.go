@@ -0,0 +1,111 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupProblemHandler() http.Handler {
+	authMiddleware := middleware.NewAuthMiddleware(middleware.NewApiKeyAuthenticator(map[string]string{"rahasia": config.DefaultTenant}, nil, nil))
+	router := httprouter.New()
+	router.PanicHandler = exeption.ErrorHandler
+	routes := config.Routes{Router: router, Chain: middleware.NewChain(authMiddleware)}
+
+	routes.GET("/api/categories/:categoryId", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		panic(exeption.NewNotFoundError("category is not found"))
+	})
+	routes.POST("/api/categories", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		panic(helper.Validator().Struct(web.CategoryCreateRequest{}))
+	})
+	routes.DELETE("/api/categories/:categoryId", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		panic(errors.New("Error 1451: foreign key constraint fails"))
+	})
+	return middleware.NewRequestIdMiddleware()(router)
+}
+
+func serveProblem(method string, target string, accept string, apiKey string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	request := httptest.NewRequest(method, "http://localhost:3000"+target, nil)
+	request.Header.Add("Accept", accept)
+	request.Header.Add("X-API-Key", apiKey)
+	request.Header.Add("X-Request-ID", "trace-1")
+	recorder := httptest.NewRecorder()
+	setupProblemHandler().ServeHTTP(recorder, request)
+
+	body := map[string]interface{}{}
+	json.Unmarshal(recorder.Body.Bytes(), &body)
+	return recorder, body
+}
+
+func TestProblemByAcceptHeader(t *testing.T) {
+	recorder, body := serveProblem(http.MethodGet, "/api/categories/7", "application/problem+json, application/json;q=0.9", "rahasia")
+
+	assert.Equal(t, 404, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "about:blank", body["type"])
+	assert.Equal(t, "Not Found", body["title"])
+	assert.Equal(t, float64(404), body["status"])
+	assert.Equal(t, "category is not found", body["detail"])
+	assert.Equal(t, "/api/categories/7", body["instance"])
+	assert.Equal(t, "trace-1", body["request_id"])
+}
+
+func TestProblemRefusedByQuality(t *testing.T) {
+	for _, accept := range []string{"application/problem+json;q=0", "application/json, application/problem+json; q=0.0", "application/problem+json;q=abc"} {
+		recorder, body := serveProblem(http.MethodGet, "/api/categories/7", accept, "rahasia")
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), accept)
+		assert.Equal(t, "NOT_FOUND", body["error_code"], accept)
+	}
+
+	for _, accept := range []string{"application/json;q=0.5, application/problem+json;q=0.1", "*/*, application/problem+json;q=0.8"} {
+		recorder, _ := serveProblem(http.MethodGet, "/api/categories/7", accept, "rahasia")
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), accept)
+	}
+
+	for _, accept := range []string{"application/json;q=0.1, application/problem+json;q=0.5", "application/problem+json, */*"} {
+		recorder, _ := serveProblem(http.MethodGet, "/api/categories/7", accept, "rahasia")
+		assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"), accept)
+	}
+}
+
+func TestProblemKindsOfErrors(t *testing.T) {
+	recorder, body := serveProblem(http.MethodPost, "/api/categories", "application/problem+json", "rahasia")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, "validation failed", body["detail"])
+	assert.Len(t, body["errors"], 1)
+
+	recorder, body = serveProblem(http.MethodGet, "/api/categories/7", "application/problem+json", "")
+	assert.Equal(t, 401, recorder.Code)
+	assert.Equal(t, "Unauthorized", body["title"])
+
+	recorder, body = serveProblem(http.MethodDelete, "/api/categories/7", "application/problem+json", "rahasia")
+	assert.Equal(t, 500, recorder.Code)
+	assert.Equal(t, "internal server error", body["detail"])
+	assert.NotEmpty(t, body["reference"])
+	assert.NotEqual(t, body["request_id"], body["reference"])
+	assert.NotContains(t, recorder.Body.String(), "1451")
+}
+
+func TestProblemByConfigSwitch(t *testing.T) {
+	recorder, body := serveProblem(http.MethodGet, "/api/categories/7", "application/json", "rahasia")
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "NOT FOUND", body["status"])
+	assert.Equal(t, "category is not found", body["data"])
+
+	exeption.ProblemJson = true
+	defer func() { exeption.ProblemJson = false }()
+
+	recorder, body = serveProblem(http.MethodGet, "/api/categories/7", "application/json", "rahasia")
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, float64(404), body["status"])
+}
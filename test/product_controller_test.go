@@ -0,0 +1,163 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+	"github.com/mrakhaf/golang-restful-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func saveCategoryWithProduct() (domain.Category, domain.Product) {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	tx, _ := db.Begin()
+	category := repository.NewCategoryRepository().Save(tenantContext(), tx, domain.Category{
+		Name: "Gadget",
+	})
+	product := repository.NewProductRepository().Save(tenantContext(), tx, domain.Product{
+		CategoryId: category.Id,
+		Name:       "Phone",
+		Price:      1000,
+	})
+	tx.Commit()
+
+	return category, product
+}
+
+func TestCreateProductSuccess(t *testing.T) {
+	category, _ := saveCategoryWithProduct()
+
+	router := setupRouter(setupTestDB())
+	requestBody := strings.NewReader(fmt.Sprintf(`{"category_id": %d, "name": "Tablet", "price": 2500}`, category.Id))
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/products", requestBody)
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 200, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+	fmt.Println(responseBody)
+
+	data := responseBody["data"].(map[string]interface{})
+	assert.Equal(t, category.Id, int(data["category_id"].(float64)))
+	assert.Equal(t, "Tablet", data["name"])
+	assert.Equal(t, 2500, int(data["price"].(float64)))
+}
+
+func TestCreateProductUnknownCategory(t *testing.T) {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	router := setupRouter(db)
+	requestBody := strings.NewReader(`{"category_id": 404, "name": "Tablet", "price": 2500}`)
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/products", requestBody)
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 400, response.StatusCode)
+}
+
+func TestListCategoryProducts(t *testing.T) {
+	category, product := saveCategoryWithProduct()
+
+	router := setupRouter(setupTestDB())
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+strconv.Itoa(category.Id)+"/products", nil)
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 200, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+
+	products := responseBody["data"].([]interface{})
+	assert.Equal(t, 1, len(products))
+	assert.Equal(t, product.Id, int(products[0].(map[string]interface{})["id"].(float64)))
+}
+
+func TestGetCategoryIncludesProductCount(t *testing.T) {
+	category, _ := saveCategoryWithProduct()
+
+	router := setupRouter(setupTestDB())
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+strconv.Itoa(category.Id), nil)
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	body, _ := io.ReadAll(recorder.Result().Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+
+	assert.Equal(t, 1, int(responseBody["data"].(map[string]interface{})["product_count"].(float64)))
+}
+
+func TestDeleteCategoryWithProductsConflict(t *testing.T) {
+	category, _ := saveCategoryWithProduct()
+
+	router := setupRouter(setupTestDB())
+	request := httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/categories/"+strconv.Itoa(category.Id), nil)
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 409, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+
+	assert.Equal(t, 409, int(responseBody["code"].(float64)))
+	assert.Equal(t, "CONFLICT", responseBody["status"])
+}
+
+func TestDeleteCategoryWithProductsCascade(t *testing.T) {
+	category, product := saveCategoryWithProduct()
+
+	db := setupTestDB()
+	router := setupRouter(db)
+	request := httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/categories/"+strconv.Itoa(category.Id)+"?cascade=true", nil)
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 200, response.StatusCode)
+
+	tx, _ := db.Begin()
+	_, err := repository.NewProductRepository().FindById(tenantContext(), tx, product.Id)
+	tx.Commit()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/mrakhaf/golang-restful-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRateLimitHandler(keys map[string]middleware.RateLimits) http.Handler {
+	limits := middleware.RateLimits{
+		Read:  middleware.RateLimit{Rate: 1, Burst: 2},
+		Write: middleware.RateLimit{Rate: 0.5, Burst: 1},
+	}
+	authMiddleware := middleware.NewAuthMiddleware(middleware.NewApiKeyAuthenticator(map[string]string{
+		"rahasia":        config.DefaultTenant,
+		"rahasia-tenant": "tenant-b",
+	}, nil, nil))
+
+	return middleware.NewChain(authMiddleware, middleware.NewRateLimitMiddleware(limits, keys, middleware.DefaultMaxBuckets)).Then(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+}
+
+func serveLimited(handler http.Handler, method string, apiKey string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, "http://localhost:3000/api/categories", nil)
+	request.Header.Add("X-API-Key", apiKey)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRateLimitExceeded(t *testing.T) {
+	handler := setupRateLimitHandler(nil)
+
+	recorder := serveLimited(handler, http.MethodGet, "rahasia")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", recorder.Header().Get("RateLimit-Remaining"))
+
+	assert.Equal(t, 200, serveLimited(handler, http.MethodGet, "rahasia").Code)
+
+	recorder = serveLimited(handler, http.MethodGet, "rahasia")
+	assert.Equal(t, 429, recorder.Code)
+	assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", recorder.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"code": 429, "status": "TOO MANY REQUESTS", "data": null, "error_code": "RATE_LIMITED"}`, recorder.Body.String())
+}
+
+func TestRateLimitSeparatesReadsWritesAndKeys(t *testing.T) {
+	handler := setupRateLimitHandler(nil)
+
+	assert.Equal(t, 200, serveLimited(handler, http.MethodDelete, "rahasia").Code)
+	recorder := serveLimited(handler, http.MethodDelete, "rahasia")
+	assert.Equal(t, 429, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+
+	// reads and other keys have buckets of their own
+	assert.Equal(t, 200, serveLimited(handler, http.MethodGet, "rahasia").Code)
+	assert.Equal(t, 200, serveLimited(handler, http.MethodDelete, "rahasia-tenant").Code)
+}
+
+func TestRateLimitPerKey(t *testing.T) {
+	relaxed := middleware.RateLimits{
+		Read:  middleware.RateLimit{Rate: 100, Burst: 100},
+		Write: middleware.RateLimit{Rate: 100, Burst: 100},
+	}
+	handler := setupRateLimitHandler(map[string]middleware.RateLimits{
+		"api_key:static-" + service.HashApiKey("rahasia")[:12]: relaxed,
+	})
+
+	for i := 0; i < 5; i++ {
+		recorder := serveLimited(handler, http.MethodGet, "rahasia")
+		assert.Equal(t, 200, recorder.Code)
+		assert.Equal(t, "100", recorder.Header().Get("RateLimit-Limit"))
+	}
+	assert.Equal(t, "2", serveLimited(handler, http.MethodGet, "rahasia-tenant").Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimitFallsBackToClientIp(t *testing.T) {
+	limits := middleware.RateLimits{Read: middleware.RateLimit{Rate: 1, Burst: 1}}
+	handler := middleware.NewRateLimitMiddleware(limits, nil, middleware.DefaultMaxBuckets)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+
+	serveFrom := func(remoteAddr string) int {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/health", nil)
+		request.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	assert.Equal(t, 200, serveFrom("192.0.2.1:1234"))
+	assert.Equal(t, 429, serveFrom("192.0.2.1:5678"))
+	assert.Equal(t, 200, serveFrom("192.0.2.2:1234"))
+}
+
+func TestRateLimitCapsBuckets(t *testing.T) {
+	limits := middleware.RateLimits{Read: middleware.RateLimit{Rate: 0.001, Burst: 1}}
+	handler := middleware.NewRateLimitMiddleware(limits, nil, 2)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+
+	serveFrom := func(remoteAddr string) int {
+		request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/health", nil)
+		request.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		time.Sleep(time.Millisecond)
+		return recorder.Code
+	}
+
+	assert.Equal(t, 200, serveFrom("192.0.2.1:1234"))
+	assert.Equal(t, 200, serveFrom("192.0.2.2:1234"))
+	// no bucket has refilled, so the least recently used one makes room
+	assert.Equal(t, 200, serveFrom("192.0.2.3:1234"))
+	assert.Equal(t, 429, serveFrom("192.0.2.2:1234"))
+	assert.Equal(t, 429, serveFrom("192.0.2.3:1234"))
+	assert.Equal(t, 200, serveFrom("192.0.2.1:1234"))
+}
+
+func TestRateLimitClientIpInFrontOfAuth(t *testing.T) {
+	authMiddleware := middleware.NewAuthMiddleware(middleware.NewApiKeyAuthenticator(map[string]string{"rahasia": config.DefaultTenant}, nil, nil))
+	clientIpRateLimit := middleware.NewRateLimitMiddleware(middleware.RateLimits{Read: middleware.RateLimit{Rate: 0.001, Burst: 2}}, nil, middleware.DefaultMaxBuckets)
+	handler := middleware.NewChain(clientIpRateLimit, authMiddleware).Then(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+
+	// made up keys fail authentication but still spend the tokens of their address
+	assert.Equal(t, 401, serveLimited(handler, http.MethodGet, "random-1").Code)
+	assert.Equal(t, 401, serveLimited(handler, http.MethodGet, "random-2").Code)
+	assert.Equal(t, 429, serveLimited(handler, http.MethodGet, "random-3").Code)
+	assert.Equal(t, 429, serveLimited(handler, http.MethodGet, "rahasia").Code)
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/controller"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/stretchr/testify/assert"
+)
+
+// echoCategoryService answers with what it was given, so the controller is tested without a database.
+type echoCategoryService struct{}
+
+func (service echoCategoryService) Create(ctx context.Context, request web.CategoryCreateRequest) web.CategoryResponse {
+	return web.CategoryResponse{Name: request.Name}
+}
+
+func (service echoCategoryService) Update(ctx context.Context, request web.CategoryUpdateRequest) web.CategoryResponse {
+	return web.CategoryResponse{Id: request.Id, Name: request.Name}
+}
+
+func (service echoCategoryService) Delete(ctx context.Context, id int) {}
+
+func (service echoCategoryService) FindById(ctx context.Context, id int) web.CategoryResponse {
+	return web.CategoryResponse{Id: id}
+}
+
+func (service echoCategoryService) FindAll(ctx context.Context) []web.CategoryResponse {
+	return nil
+}
+
+func serveDecoding(method string, target string, contentType string, body io.Reader) (int, map[string]interface{}) {
+	crudController := controller.NewCrudController[web.CategoryCreateRequest, web.CategoryUpdateRequest, web.CategoryResponse](echoCategoryService{}, "categoryId", func(request *web.CategoryUpdateRequest, id int) {
+		request.Id = id
+	})
+	router := httprouter.New()
+	router.PanicHandler = exeption.ErrorHandler
+	router.POST("/api/categories", crudController.Create)
+	router.PUT("/api/categories/:categoryId", crudController.Update)
+	router.GET("/api/categories/:categoryId", crudController.FindById)
+
+	request := httptest.NewRequest(method, "http://localhost:3000"+target, body)
+	if contentType != "" {
+		request.Header.Add("Content-Type", contentType)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	responseBody := map[string]interface{}{}
+	json.Unmarshal(recorder.Body.Bytes(), &responseBody)
+	return recorder.Code, responseBody
+}
+
+func TestDecodingAcceptsJson(t *testing.T) {
+	status, body := serveDecoding(http.MethodPut, "/api/categories/7", "application/json; charset=utf-8", strings.NewReader(`{"name": "Gadget"}`))
+
+	assert.Equal(t, 200, status)
+	assert.Equal(t, map[string]interface{}{"id": float64(7), "name": "Gadget"}, body["data"])
+}
+
+func TestDecodingRejectsContentType(t *testing.T) {
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded", "application/json; charset=latin1"} {
+		status, body := serveDecoding(http.MethodPost, "/api/categories", contentType, strings.NewReader(`{"name": "Gadget"}`))
+		assert.Equal(t, 415, status, contentType)
+		assert.Equal(t, "UNSUPPORTED MEDIA TYPE", body["status"])
+	}
+}
+
+func TestDecodingRejectsMalformedBodies(t *testing.T) {
+	tests := map[string]string{
+		``:                                 "request body must not be empty",
+		`{"name": "Gadget"`:                "request body contains malformed JSON",
+		`{"name": "Gadget",}`:              "request body contains malformed JSON at offset 19",
+		`{"name": 42}`:                     "field name must be a JSON string",
+		`["Gadget"]`:                       "request body must be a JSON object",
+		`{"name": "Gadget", "color": "x"}`: `request body contains unknown field "color"`,
+		`{"name": "Gadget"} {"name": "x"}`: "request body must contain a single JSON value",
+	}
+	for requestBody, message := range tests {
+		status, body := serveDecoding(http.MethodPost, "/api/categories", "application/json", strings.NewReader(requestBody))
+		assert.Equal(t, 400, status, requestBody)
+		assert.Equal(t, message, body["data"], requestBody)
+	}
+}
+
+func TestDecodingCapsBodySize(t *testing.T) {
+	maxRequestBodyBytes := controller.MaxRequestBodyBytes
+	controller.MaxRequestBodyBytes = 64
+	defer func() { controller.MaxRequestBodyBytes = maxRequestBodyBytes }()
+
+	status, body := serveDecoding(http.MethodPost, "/api/categories", "application/json", strings.NewReader(`{"name": "`+strings.Repeat("a", 100)+`"}`))
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "request body must not be larger than 64 bytes", body["data"])
+}
+
+func TestDecodingCapsUploadSize(t *testing.T) {
+	maxUploadBytes := controller.MaxUploadBytes
+	controller.MaxUploadBytes = 64
+	defer func() { controller.MaxUploadBytes = maxUploadBytes }()
+
+	router := httprouter.New()
+	router.PanicHandler = exeption.ErrorHandler
+	router.POST("/api/admin/restore", controller.NewBackupController(nil).Restore)
+	router.POST("/api/categories/import", controller.NewCategoryController(nil).Import)
+
+	archive := `{"schema_version": 1, "category_count": 1, "categories": [{"id": 1, "name": "` + strings.Repeat("a", 100) + `"}]}`
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/admin/restore", strings.NewReader(archive))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"error_code":"REQUEST_BODY_INVALID"`)
+	assert.Contains(t, recorder.Body.String(), "request body must not be larger than 64 bytes")
+
+	requestBody := &bytes.Buffer{}
+	form := multipart.NewWriter(requestBody)
+	file, _ := form.CreateFormFile("file", "categories.csv")
+	file.Write([]byte("name\n" + strings.Repeat("Gadget\n", 20)))
+	form.Close()
+	request = httptest.NewRequest(http.MethodPost, "http://localhost:3000/api/categories/import", requestBody)
+	request.Header.Add("Content-Type", form.FormDataContentType())
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"error_code":"REQUEST_BODY_INVALID"`)
+}
+
+func TestDecodingValidatesPathParams(t *testing.T) {
+	for _, categoryId := range []string{"abc", "0", "-3", "99999999999999999999"} {
+		status, body := serveDecoding(http.MethodGet, "/api/categories/"+categoryId, "", nil)
+		assert.Equal(t, 400, status, categoryId)
+		assert.Equal(t, "categoryId must be a positive integer", body["data"])
+	}
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRequestIdHandler() http.Handler {
+	router := httprouter.New()
+	router.PanicHandler = exeption.ErrorHandler
+	router.GET("/echo", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		writer.Write([]byte(helper.RequestIdFromContext(request.Context())))
+	})
+	router.GET("/fail", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		panic(exeption.NewNotFoundError("category is not found"))
+	})
+	router.GET("/crash", func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		panic(errors.New("boom"))
+	})
+	return middleware.NewRequestIdMiddleware()(router)
+}
+
+func serveRequestId(handler http.Handler, target string, requestId string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000"+target, nil)
+	if requestId != "" {
+		request.Header.Add("X-Request-ID", requestId)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRequestIdGenerated(t *testing.T) {
+	handler := setupRequestIdHandler()
+
+	first := serveRequestId(handler, "/echo", "")
+	second := serveRequestId(handler, "/echo", "")
+
+	assert.Len(t, first.Header().Get("X-Request-ID"), 32)
+	assert.Equal(t, first.Header().Get("X-Request-ID"), first.Body.String())
+	assert.NotEqual(t, first.Body.String(), second.Body.String())
+}
+
+func TestRequestIdAccepted(t *testing.T) {
+	handler := setupRequestIdHandler()
+
+	recorder := serveRequestId(handler, "/echo", "client-42.a")
+	assert.Equal(t, "client-42.a", recorder.Header().Get("X-Request-ID"))
+	assert.Equal(t, "client-42.a", recorder.Body.String())
+
+	// an id that could forge log lines is replaced
+	recorder = serveRequestId(handler, "/echo", "evil\" status=200")
+	assert.NotEqual(t, "evil\" status=200", recorder.Body.String())
+	assert.Len(t, recorder.Body.String(), 32)
+}
+
+func TestRequestIdInErrorBody(t *testing.T) {
+	handler := setupRequestIdHandler()
+
+	for _, target := range []string{"/fail", "/crash"} {
+		recorder := serveRequestId(handler, target, "trace-1")
+
+		var body map[string]interface{}
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Equal(t, "trace-1", body["request_id"])
+		assert.Equal(t, "trace-1", recorder.Header().Get("X-Request-ID"))
+	}
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupScopeRouter() http.Handler {
+	ok := func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {}
+
+	router := httprouter.New()
+	router.GET("/api/categories", middleware.RequireScopes(ok, "categories:read"))
+	router.DELETE("/api/categories/:categoryId", middleware.RequireScopes(ok, "categories:write"))
+	router.PanicHandler = exeption.ErrorHandler
+
+	return middleware.NewAuthMiddleware(middleware.NewJwtAuthenticator(testJwtConfig()))(router)
+}
+
+func serveScoped(method string, scope string) (int, map[string]interface{}) {
+	request := httptest.NewRequest(method, "http://localhost:3000/api/categories", nil)
+	if method == http.MethodDelete {
+		request = httptest.NewRequest(method, "http://localhost:3000/api/categories/1", nil)
+	}
+	request.Header.Add("Authorization", "Bearer "+signJwt(jwt.SigningMethodHS256, "", jwtSecret, jwtClaims(jwt.MapClaims{"scope": scope})))
+
+	recorder := httptest.NewRecorder()
+	setupScopeRouter().ServeHTTP(recorder, request)
+
+	var body map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &body)
+	return recorder.Code, body
+}
+
+func TestScopeGranted(t *testing.T) {
+	status, _ := serveScoped(http.MethodGet, "categories:read")
+	assert.Equal(t, 200, status)
+
+	status, _ = serveScoped(http.MethodDelete, "categories:read categories:write")
+	assert.Equal(t, 200, status)
+}
+
+func TestScopeMissingForbidden(t *testing.T) {
+	status, body := serveScoped(http.MethodDelete, "categories:read")
+	assert.Equal(t, 403, status)
+	assert.Equal(t, 403, int(body["code"].(float64)))
+	assert.Equal(t, "FORBIDDEN", body["status"])
+	assert.Equal(t, "missing scope categories:write", body["data"])
+
+	status, _ = serveScoped(http.MethodGet, "")
+	assert.Equal(t, 403, status)
+}
+
+func TestApiKeyScopeForbidden(t *testing.T) {
+	truncateApiKey()
+	status, body := serve(setupRouter(setupTestDB()), http.MethodPost, "http://localhost:3000/api/admin/api-keys", "rahasia", `{"name": "reader", "owner": "team", "scopes": ["categories:read"]}`)
+	assert.Equal(t, 200, status)
+	key := body["data"].(map[string]interface{})["key"].(string)
+
+	status, _ = serve(setupRouter(setupTestDB()), http.MethodGet, "http://localhost:3000/api/categories", key, "")
+	assert.Equal(t, 200, status)
+
+	status, body = serve(setupRouter(setupTestDB()), http.MethodDelete, "http://localhost:3000/api/categories/1", key, "")
+	assert.Equal(t, 403, status)
+	assert.Equal(t, "FORBIDDEN", body["status"])
+
+	status, _ = serve(setupRouter(setupTestDB()), http.MethodGet, "http://localhost:3000/api/admin/api-keys", key, "")
+	assert.Equal(t, 403, status)
+}
@@ -0,0 +1,170 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConnector answers every statement without a database: exec affects 2 rows, a query returns 3
+// and statements containing SLEEP take 20ms.
+type fakeConnector struct{}
+
+func (connector fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+func (connector fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct{}
+
+func (conn fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (conn fakeConn) Close() error {
+	return nil
+}
+
+func (conn fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (conn fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if strings.Contains(query, "SLEEP") {
+		time.Sleep(20 * time.Millisecond)
+	}
+	return driver.RowsAffected(2), nil
+}
+
+func (conn fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{left: 3}, nil
+}
+
+type fakeTx struct{}
+
+func (tx fakeTx) Commit() error {
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeRows struct {
+	left int
+}
+
+func (rows *fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (rows *fakeRows) Close() error {
+	return nil
+}
+
+func (rows *fakeRows) Next(dest []driver.Value) error {
+	if rows.left == 0 {
+		return io.EOF
+	}
+	dest[0] = int64(rows.left)
+	rows.left--
+	return nil
+}
+
+func setupLoggedDB(output *bytes.Buffer, level slog.Level, logArgs bool) *sql.DB {
+	return sql.OpenDB(repository.NewLoggingConnector(fakeConnector{}, repository.SqlLogConfig{
+		Logger:        slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: level})),
+		SlowThreshold: 10 * time.Millisecond,
+		LogArgs:       logArgs,
+		RedactColumns: repository.DefaultRedactColumns,
+	}))
+}
+
+func TestSqlLogStatements(t *testing.T) {
+	output := &bytes.Buffer{}
+	db := setupLoggedDB(output, slog.LevelDebug, true)
+	ctx := helper.WithRequestId(context.Background(), "trace-1")
+
+	tx, err := db.Begin()
+	assert.Nil(t, err)
+	_, err = tx.ExecContext(ctx, "UPDATE api_key SET name = ? WHERE key_hash = ?", "ci", "0123abcd")
+	assert.Nil(t, err)
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM category WHERE tenant_id = ?", "default")
+	assert.Nil(t, err)
+	for rows.Next() {
+	}
+	rows.Close()
+	assert.Nil(t, tx.Commit())
+
+	records := logRecords(output)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "DEBUG", records[0]["level"])
+	assert.Equal(t, "UPDATE api_key SET name = ? WHERE key_hash = ?", records[0]["statement"])
+	assert.Equal(t, float64(2), records[0]["rows"])
+	assert.Equal(t, "trace-1", records[0]["request_id"])
+	assert.Equal(t, []interface{}{"ci", "[REDACTED]"}, records[0]["args"])
+	assert.NotContains(t, output.String(), "0123abcd")
+
+	assert.Equal(t, float64(3), records[1]["rows"])
+	assert.Equal(t, []interface{}{"default"}, records[1]["args"])
+}
+
+func TestSqlLogRedactsInsertedColumns(t *testing.T) {
+	output := &bytes.Buffer{}
+	db := setupLoggedDB(output, slog.LevelDebug, true)
+
+	_, err := db.Exec("INSERT INTO oauth_client (client_id, secret_hash, name) values(?, ?, ?)", "cl_1", "s3cr3t", "ci")
+	assert.Nil(t, err)
+
+	records := logRecords(output)
+	assert.Equal(t, []interface{}{"cl_1", "[REDACTED]", "ci"}, records[0]["args"])
+}
+
+func TestSqlLogOnlyTypesOfUnplacedArgs(t *testing.T) {
+	output := &bytes.Buffer{}
+	db := setupLoggedDB(output, slog.LevelDebug, true)
+
+	_, err := db.Exec("INSERT INTO oauth_client VALUES (?, ?)", "cl_1", "s3cr3t")
+	assert.Nil(t, err)
+	_, err = db.Exec("DELETE FROM api_key WHERE tenant_id = ? AND key_hash IN (?, ?)", "default", "0123abcd", int64(7))
+	assert.Nil(t, err)
+	_, err = db.Exec("INSERT INTO category (id, name) VALUES (?, LOWER(?)) ON DUPLICATE KEY UPDATE name = ?", int64(1), "Gadget", "Gadget")
+	assert.Nil(t, err)
+
+	records := logRecords(output)
+	assert.Equal(t, []interface{}{"[string]", "[string]"}, records[0]["args"])
+	assert.Equal(t, []interface{}{"default", "[string]", "[int64]"}, records[1]["args"])
+	assert.Equal(t, []interface{}{"1", "[string]", "Gadget"}, records[2]["args"])
+	assert.NotContains(t, output.String(), "s3cr3t")
+	assert.NotContains(t, output.String(), "0123abcd")
+}
+
+func TestSqlLogSlowQuery(t *testing.T) {
+	output := &bytes.Buffer{}
+	db := setupLoggedDB(output, slog.LevelWarn, false)
+
+	_, err := db.Exec("DELETE FROM category")
+	assert.Nil(t, err)
+	_, err = db.Exec("SELECT SLEEP(1)")
+	assert.Nil(t, err)
+
+	records := logRecords(output)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "WARN", records[0]["level"])
+	assert.Equal(t, "slow sql", records[0]["msg"])
+	assert.NotContains(t, records[0], "args")
+}
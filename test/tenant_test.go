@@ -0,0 +1,109 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/domain"
+	"github.com/mrakhaf/golang-restful-api/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func saveOtherTenantCategory() domain.Category {
+	db := setupTestDB()
+	truncateCategory(db)
+
+	tx, _ := db.Begin()
+	categoryRepository := repository.NewCategoryRepository()
+	category := categoryRepository.Save(helper.WithTenant(context.Background(), "tenant-b"), tx, domain.Category{
+		Name: "Tenant B Gadget",
+	})
+	tx.Commit()
+
+	return category
+}
+
+func assertNotFound(t *testing.T, response *http.Response) {
+	assert.Equal(t, 404, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	var responseBody map[string]interface{}
+	json.Unmarshal(body, &responseBody)
+
+	assert.Equal(t, 404, int(responseBody["code"].(float64)))
+	assert.Equal(t, "NOT FOUND", responseBody["status"])
+}
+
+func TestCrossTenantFindByIdNotFound(t *testing.T) {
+	category := saveOtherTenantCategory()
+
+	router := setupRouter(setupTestDB())
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+strconv.Itoa(category.Id), nil)
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	assertNotFound(t, recorder.Result())
+}
+
+func TestCrossTenantUpdateNotFound(t *testing.T) {
+	category := saveOtherTenantCategory()
+
+	router := setupRouter(setupTestDB())
+	requestBody := strings.NewReader(`{"name": "Hijacked"}`)
+	request := httptest.NewRequest(http.MethodPut, "http://localhost:3000/api/categories/"+strconv.Itoa(category.Id), requestBody)
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	assertNotFound(t, recorder.Result())
+}
+
+func TestCrossTenantDeleteNotFound(t *testing.T) {
+	category := saveOtherTenantCategory()
+
+	db := setupTestDB()
+	router := setupRouter(db)
+	request := httptest.NewRequest(http.MethodDelete, "http://localhost:3000/api/categories/"+strconv.Itoa(category.Id), nil)
+	request.Header.Add("X-API-Key", "rahasia")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	assertNotFound(t, recorder.Result())
+
+	tx, _ := db.Begin()
+	categoryRepository := repository.NewCategoryRepository()
+	stored, err := categoryRepository.FindById(helper.WithTenant(context.Background(), "tenant-b"), tx, category.Id)
+	tx.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, "Tenant B Gadget", stored.Name)
+}
+
+func TestOwnTenantFindByIdSuccess(t *testing.T) {
+	category := saveOtherTenantCategory()
+
+	router := setupRouter(setupTestDB())
+	request := httptest.NewRequest(http.MethodGet, "http://localhost:3000/api/categories/"+strconv.Itoa(category.Id), nil)
+	request.Header.Add("X-API-Key", "rahasia-tenant")
+
+	recorder := httptest.NewRecorder()
+
+	router.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	assert.Equal(t, 200, response.StatusCode)
+}
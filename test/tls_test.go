@@ -0,0 +1,194 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	der         []byte
+}
+
+// newTestCertificate signs template with parent, or self-signs it when parent is nil.
+func newTestCertificate(template *x509.Certificate, parent *testCertificate) testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	helper.PanicIfError(err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	helper.PanicIfError(err)
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.certificate, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	helper.PanicIfError(err)
+
+	certificate, err := x509.ParseCertificate(der)
+	helper.PanicIfError(err)
+	return testCertificate{certificate: certificate, key: key, der: der}
+}
+
+func newTestCa(name string) testCertificate {
+	return newTestCertificate(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+}
+
+func newTestClientCertificate(ca testCertificate, commonName string, uris ...string) testCertificate {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, uri := range uris {
+		parsed, _ := url.Parse(uri)
+		template.URIs = append(template.URIs, parsed)
+	}
+	return newTestCertificate(template, &ca)
+}
+
+func (certificate testCertificate) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{certificate.der}, PrivateKey: certificate.key}
+}
+
+func (certificate testCertificate) writePem(t *testing.T, name string) (string, string) {
+	certFile := filepath.Join(t.TempDir(), name+".crt")
+	keyFile := filepath.Join(t.TempDir(), name+".key")
+
+	helper.PanicIfError(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.der}), 0600))
+	keyDer, err := x509.MarshalECPrivateKey(certificate.key)
+	helper.PanicIfError(err)
+	helper.PanicIfError(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	return certFile, keyFile
+}
+
+// startTlsServer serves the tenant and principal id over TLS, verifying client certificates against ca.
+func startTlsServer(t *testing.T, ca testCertificate, requireClientCert bool) *httptest.Server {
+	server := newTestCertificate(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: "localhost"},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	certFile, keyFile := server.writePem(t, "server")
+	caFile, _ := ca.writePem(t, "ca")
+
+	authMiddleware := middleware.NewAuthMiddleware(middleware.NewClientCertAuthenticator(map[string]middleware.ClientCertIdentity{
+		"spiffe://internal/orders": {TenantId: "tenant-b", Scopes: []string{"categories:read"}},
+		"billing":                  {TenantId: config.DefaultTenant, Scopes: []string{"categories:read"}},
+	}), middleware.NewApiKeyAuthenticator(map[string]string{"rahasia": config.DefaultTenant}, nil, nil))
+	handler := authMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		principal, _ := helper.PrincipalFromContext(request.Context())
+		writer.Write([]byte(principal.TenantId + " " + principal.Id))
+	}))
+
+	httpServer := httptest.NewUnstartedServer(handler)
+	tlsConfig, err := config.LoadTlsConfig(config.TlsSettings{CertFile: certFile, KeyFile: keyFile, ClientCaFile: caFile, RequireClientCert: requireClientCert})
+	assert.Nil(t, err)
+	httpServer.TLS = tlsConfig
+	httpServer.StartTLS()
+	return httpServer
+}
+
+func tlsGet(ca testCertificate, serverUrl string, certificates []tls.Certificate, apiKey string) (int, string, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certificates}}}
+
+	request, _ := http.NewRequest(http.MethodGet, serverUrl+"/api/categories", nil)
+	if apiKey != "" {
+		request.Header.Set("X-API-Key", apiKey)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, "", err
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	return response.StatusCode, string(body), nil
+}
+
+func TestClientCertificateIdentity(t *testing.T) {
+	ca := newTestCa("internal ca")
+	server := startTlsServer(t, ca, false)
+	defer server.Close()
+
+	client := newTestClientCertificate(ca, "orders", "spiffe://internal/orders")
+	status, body, err := tlsGet(ca, server.URL, []tls.Certificate{client.tlsCertificate()}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "tenant-b spiffe://internal/orders", body)
+
+	client = newTestClientCertificate(ca, "billing")
+	status, body, err = tlsGet(ca, server.URL, []tls.Certificate{client.tlsCertificate()}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, config.DefaultTenant+" billing", body)
+}
+
+func TestClientCertificateOptional(t *testing.T) {
+	ca := newTestCa("internal ca")
+	server := startTlsServer(t, ca, false)
+	defer server.Close()
+
+	status, _, err := tlsGet(ca, server.URL, nil, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 401, status)
+
+	status, body, err := tlsGet(ca, server.URL, nil, "rahasia")
+	assert.Nil(t, err)
+	assert.Equal(t, 200, status)
+	assert.True(t, strings.HasPrefix(body, config.DefaultTenant+" static-"))
+
+	// verified, but not mapped to an identity
+	client := newTestClientCertificate(ca, "unknown")
+	status, _, err = tlsGet(ca, server.URL, []tls.Certificate{client.tlsCertificate()}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 401, status)
+}
+
+func TestClientCertificateRejected(t *testing.T) {
+	ca := newTestCa("internal ca")
+	server := startTlsServer(t, ca, true)
+	defer server.Close()
+
+	_, _, err := tlsGet(ca, server.URL, nil, "rahasia")
+	assert.NotNil(t, err)
+
+	foreign := newTestClientCertificate(newTestCa("foreign ca"), "billing")
+	_, _, err = tlsGet(ca, server.URL, []tls.Certificate{foreign.tlsCertificate()}, "")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/config"
+	"github.com/mrakhaf/golang-restful-api/controller"
+	"github.com/mrakhaf/golang-restful-api/middleware"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/mrakhaf/golang-restful-api/repository"
+	"github.com/mrakhaf/golang-restful-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// testUsageQuota counts usage without limiting it, so the other tests are not affected.
+var testUsageQuota = service.UsageQuota{}
+
+func truncateUsage() {
+	setupTestDB().Exec("DELETE FROM usage_counter")
+}
+
+func TestUsageCountsOwnRequests(t *testing.T) {
+	truncateUsage()
+	truncateCategory(setupTestDB())
+	router := setupRouter(setupTestDB())
+
+	serve(router, http.MethodGet, "http://localhost:3000/api/categories", "rahasia", "")
+	serve(router, http.MethodPost, "http://localhost:3000/api/categories", "rahasia", `{"name": "Gadget"}`)
+	serve(router, http.MethodGet, "http://localhost:3000/api/categories", "rahasia-tenant", "")
+
+	status, body := serve(router, http.MethodGet, "http://localhost:3000/api/usage", "rahasia", "")
+	assert.Equal(t, 200, status)
+
+	data := body["data"].(map[string]interface{})
+	today := data["today"].(map[string]interface{})
+	assert.Equal(t, 1, int(today["read"].(float64)))
+	assert.Equal(t, 1, int(today["write"].(float64)))
+	assert.Equal(t, 2, int(data["month"].(map[string]interface{})["total"].(float64)))
+	assert.Equal(t, 1, len(data["days"].([]interface{})))
+}
+
+func TestUsageQuotaExceeded(t *testing.T) {
+	truncateUsage()
+	db := setupTestDB()
+	usageService := service.NewUsageService(repository.NewUsageRepository(), db, service.UsageQuota{Daily: 2}, nil)
+	router := httprouter.New()
+	router.GET("/api/usage", controller.NewUsageController(usageService).FindOwn)
+	authMiddleware := middleware.NewAuthMiddleware(middleware.NewApiKeyAuthenticator(map[string]string{"rahasia": config.DefaultTenant}, nil, nil))
+	handler := middleware.NewChain(authMiddleware, middleware.NewUsageMiddleware(usageService)).Then(router)
+
+	status, _ := serve(handler, http.MethodGet, "http://localhost:3000/api/usage", "rahasia", "")
+	assert.Equal(t, 200, status)
+	status, _ = serve(handler, http.MethodGet, "http://localhost:3000/api/usage", "rahasia", "")
+	assert.Equal(t, 200, status)
+
+	status, body := serve(handler, http.MethodGet, "http://localhost:3000/api/usage", "rahasia", "")
+	assert.Equal(t, 429, status)
+	assert.Equal(t, "TOO MANY REQUESTS", body["status"])
+	assert.Equal(t, "daily quota exceeded", body["data"])
+
+	// counters already persisted are loaded by a new instance
+	restarted := service.NewUsageService(repository.NewUsageRepository(), db, service.UsageQuota{Daily: 2}, nil)
+	handler = middleware.NewChain(authMiddleware, middleware.NewUsageMiddleware(restarted)).Then(router)
+	status, _ = serve(handler, http.MethodGet, "http://localhost:3000/api/usage", "rahasia", "")
+	assert.Equal(t, 429, status)
+}
+
+func TestUsageQuotaSharedBetweenInstances(t *testing.T) {
+	truncateUsage()
+	db := setupTestDB()
+	ctx := context.Background()
+	principal := web.Principal{Kind: "api_key", Id: "static:rahasia", TenantId: config.DefaultTenant}
+	first := service.NewUsageService(repository.NewUsageRepository(), db, service.UsageQuota{Daily: 2}, nil)
+	second := service.NewUsageService(repository.NewUsageRepository(), db, service.UsageQuota{Daily: 2}, nil)
+
+	exceeded, _ := first.Count(ctx, principal, "read")
+	assert.Equal(t, "", exceeded)
+	exceeded, _ = second.Count(ctx, principal, "read")
+	assert.Equal(t, "", exceeded)
+
+	// each flush reloads the totals, taking in what the other instance flushed before
+	first.Flush(ctx)
+	second.Flush(ctx)
+	first.Flush(ctx)
+
+	exceeded, _ = first.Count(ctx, principal, "read")
+	assert.Equal(t, "daily", exceeded)
+	exceeded, _ = second.Count(ctx, principal, "read")
+	assert.Equal(t, "daily", exceeded)
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrakhaf/golang-restful-api/exeption"
+	"github.com/mrakhaf/golang-restful-api/helper"
+	"github.com/mrakhaf/golang-restful-api/model/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveValidation(request interface{}, acceptLanguage string, accept string) map[string]interface{} {
+	validate := helper.Validator()
+	router := httprouter.New()
+	router.PanicHandler = exeption.ErrorHandler
+	router.POST("/validate", func(writer http.ResponseWriter, httpRequest *http.Request, params httprouter.Params) {
+		helper.PanicIfError(validate.Struct(request))
+	})
+
+	httpRequest := httptest.NewRequest(http.MethodPost, "http://localhost:3000/validate", nil)
+	httpRequest.Header.Add("Accept-Language", acceptLanguage)
+	httpRequest.Header.Add("Accept", accept)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httpRequest)
+
+	body := map[string]interface{}{}
+	json.Unmarshal(recorder.Body.Bytes(), &body)
+	return body
+}
+
+func TestValidationErrorsStructured(t *testing.T) {
+	body := serveValidation(web.CategoryCreateRequest{Name: strings.Repeat("a", 201)}, "", "")
+
+	assert.Equal(t, float64(400), body["code"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"field":   "name",
+		"rule":    "max",
+		"param":   "200",
+		"message": "name must be a maximum of 200 characters in length",
+	}}, body["data"])
+}
+
+func TestValidationErrorsNestedField(t *testing.T) {
+	body := serveValidation(web.ApiKeyCreateRequest{Name: "ci", Owner: "ops", Scopes: []string{"admin", ""}}, "en", "")
+
+	validationError := body["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "scopes[1]", validationError["field"])
+	assert.Equal(t, "required", validationError["rule"])
+	assert.NotContains(t, validationError, "param")
+}
+
+func TestValidationErrorsIndonesian(t *testing.T) {
+	body := serveValidation(web.CategoryCreateRequest{}, "id-ID,id;q=0.9,en;q=0.8", "")
+	validationError := body["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "name wajib diisi", validationError["message"])
+
+	// the best supported locale wins, unsupported ones fall back to English
+	body = serveValidation(web.CategoryCreateRequest{}, "fr;q=1, en;q=0.5, id;q=0.7", "")
+	validationError = body["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "name wajib diisi", validationError["message"])
+
+	body = serveValidation(web.CategoryCreateRequest{}, "fr", "")
+	validationError = body["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "name is a required field", validationError["message"])
+}
+
+func TestValidationErrorsProblem(t *testing.T) {
+	body := serveValidation(web.CategoryCreateRequest{}, "", "application/problem+json")
+
+	assert.Equal(t, "validation failed", body["detail"])
+	validationError := body["errors"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "name", validationError["field"])
+}